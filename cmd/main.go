@@ -4,39 +4,116 @@ import (
 	"log"
 	"time"
 
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/alerts"
+	"github.com/poportss/go-challenge-flight-price/internal/config"
 	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
 	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
 	"github.com/poportss/go-challenge-flight-price/internal/util"
 )
 
 func main() {
 	log.Println("🚀 Starting Flight Price Aggregator...")
 
-	jwtSecret := util.EnvOr("JWT_SECRET", "devsecret")
-	port := util.EnvOr("PORT", "8080")
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("❌ Failed to load configuration: %v", err)
+	}
+
+	if cfg.AirportsFile != "" {
+		if err := airports.LoadFile(cfg.AirportsFile); err != nil {
+			log.Fatalf("❌ Failed to load airports: %v", err)
+		}
+		log.Printf("✓ Airport reference data loaded from %s", cfg.AirportsFile)
+	}
+	log.Printf("✓ %d airports known; other codes are rejected", airports.Count())
 
 	// Cache with automatic cleanup every 1 minute
-	cache := flights.NewInMemoryTTL()
+	cache := flights.NewResponseCache(cfg.Cache.MaxEntries, cfg.Cache.MaxBytes)
 	cache.StartCleanup(1 * time.Minute)
-	log.Println("✓ Cache initialized with automatic cleanup")
+	defer cache.Stop()
+	log.Printf("✓ Cache initialized with automatic cleanup (max %d entries, %d bytes)", cfg.Cache.MaxEntries, cfg.Cache.MaxBytes)
+
+	provs, err := providers.Build(cfg.Providers)
+	if err != nil {
+		log.Fatalf("❌ Failed to build providers: %v", err)
+	}
+	for _, p := range provs {
+		log.Printf("✓ Provider %s enabled", p.Name())
+	}
 
-	log.Printf("No providers configured yet. They will be set up during login.")
+	// Create service with the configured timeout and cache
+	svc := flights.NewService(provs, time.Duration(cfg.SearchTimeout), cache)
+	log.Printf("✓ Service initialized with %d provider(s)", len(provs))
 
-	// Create service with 1-minute timeout and cache
-	svc := flights.NewService(nil, 1*time.Minute, cache)
-	log.Printf("✓ Service initialized with %d provider(s)", 0)
+	switch {
+	case cfg.FX.RatesURL != "":
+		ttl := time.Duration(cfg.FX.TTL)
+		if ttl <= 0 {
+			ttl = 1 * time.Hour
+		}
+		svc.SetRateSource(fx.NewHTTPSource(util.NewHTTPClient(10*time.Second), cfg.FX.RatesURL, ttl))
+		log.Printf("✓ FX rates from %s (refreshed every %s)", cfg.FX.RatesURL, ttl)
+	case cfg.FX.RatesFile != "":
+		rates, err := fx.NewFileSource(cfg.FX.RatesFile)
+		if err != nil {
+			log.Fatalf("❌ Failed to load FX rates: %v", err)
+		}
+		svc.SetRateSource(rates)
+		log.Printf("✓ FX rates loaded from %s", cfg.FX.RatesFile)
+	default:
+		log.Printf("⚠ No FX rates configured: quotes not in the requested currency are dropped")
+	}
+
+	if cfg.HistoryFile != "" {
+		store, err := history.OpenFileStore(cfg.HistoryFile, time.Duration(cfg.HistoryRetention))
+		if err != nil {
+			log.Fatalf("❌ Failed to open price history: %v", err)
+		}
+		defer store.Close()
+		svc.SetObservationStore(store)
+		log.Printf("✓ Price history recorded to %s (kept for %s)", cfg.HistoryFile, time.Duration(cfg.HistoryRetention))
+	} else {
+		svc.SetObservationStore(history.NewMemoryStore())
+		log.Printf("⚠ No history file configured: price history is kept in memory only")
+	}
+
+	var alertStore alerts.Store = alerts.NewMemoryStore()
+	if cfg.Alerts.File != "" {
+		if alertStore, err = alerts.OpenFileStore(cfg.Alerts.File); err != nil {
+			log.Fatalf("❌ Failed to open alerts: %v", err)
+		}
+	}
+	webhook := alerts.NewWebhook(alerts.NewWebhookClient(10*time.Second), cfg.Alerts.WebhookAttempts, 2*time.Second)
+	scheduler := alerts.NewScheduler(alertStore, svc, webhook, time.Duration(cfg.Alerts.Interval))
+	scheduler.Start()
+	defer scheduler.Stop()
+	log.Printf("✓ Price alerts checked every %s", time.Duration(cfg.Alerts.Interval))
 
 	// Create and start HTTP server
-	server := httpserver.New(svc, jwtSecret)
+	server := httpserver.New(svc, cfg.JWTSecret, httpserver.WithAlerts(alertStore), httpserver.WithAllowedOrigins(cfg.WSOrigins...))
 
-	log.Printf("🌐 Server running at http://localhost:%s", port)
+	log.Printf("🌐 Server running at http://localhost:%s", cfg.Port)
 	log.Printf("📖 Available endpoints:")
 	log.Printf("   POST /login - Authentication")
 	log.Printf("   GET  /flights/search - Search flights")
+	log.Printf("   GET  /flights/search/stream - Stream search results (SSE or NDJSON)")
+	log.Printf("   POST /flights/multi-city - Multi-city search")
+	log.Printf("   GET  /flights/calendar - Flexible-date price calendar")
+	log.Printf("   GET  /flights/explore - Cheapest dates within a window")
+	log.Printf("   GET  /flights/anywhere - Destinations within a budget")
+	log.Printf("   POST /flights/meetup - Meet-up destinations for several origins")
 	log.Printf("   GET  /flights/history - Flight price history")
 	log.Printf("   GET  /sse/:route - Server-Sent Events stream")
+	log.Printf("   GET  /cache/stats - Response cache counters")
+	log.Printf("   GET  /sse/stats - Route stream subscribers")
+	log.Printf("   GET  /ws/watch - WebSocket multi-route watch")
+	log.Printf("   POST /alerts, GET|PATCH|DELETE /alerts/:id - Price alerts")
 
-	if err := server.Run(":" + port); err != nil {
+	if err := server.Run(":" + cfg.Port); err != nil {
 		log.Fatalf("❌ Failed to start server: %v", err)
 	}
 }
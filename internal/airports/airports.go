@@ -0,0 +1,246 @@
+// Package airports exposes the embedded airport and city reference data.
+package airports
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+
+	// embed the IANA database so lookups don't depend on the host's zoneinfo
+	_ "time/tzdata"
+)
+
+//go:embed airports.csv
+var airportsCSV string
+
+// ErrInvalidCode is returned for codes that are neither a known airport nor a known city
+var ErrInvalidCode = errors.New("invalid IATA code")
+
+// ErrUnknownTimezone is returned when a local time is given for an airport
+// missing from the reference data, so its UTC offset can't be known
+var ErrUnknownTimezone = errors.New("unknown airport time zone")
+
+type dataset struct {
+	airports  map[string]domain.Airport
+	locations map[string]*time.Location
+	cities    map[string][]string // city code → airport codes, sorted
+}
+
+// embedded is the dataset compiled into the binary
+var embedded = sync.OnceValue(func() *dataset {
+	d, err := parse(strings.NewReader(airportsCSV))
+	if err != nil {
+		panic(fmt.Sprintf("airports: invalid embedded dataset: %v", err))
+	}
+	return d
+})
+
+// loaded replaces the embedded dataset once LoadFile succeeds
+var loaded atomic.Pointer[dataset]
+
+func data() *dataset {
+	if d := loaded.Load(); d != nil {
+		return d
+	}
+	return embedded()
+}
+
+// LoadFile replaces the embedded reference data with a CSV file in the same
+// format (iata,name,city,city_code,country,latitude,longitude,timezone), such
+// as a full IATA export. The embedded data is kept when the file is invalid.
+func LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("airports: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	d, err := parse(f)
+	if err != nil {
+		return fmt.Errorf("airports: %s: %w", path, err)
+	}
+	loaded.Store(d)
+	return nil
+}
+
+// Count returns the number of airports in the reference data
+func Count() int {
+	return len(data().airports)
+}
+
+func parse(r io.Reader) (*dataset, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) < 2 {
+		return nil, errors.New("no airports")
+	}
+
+	d := &dataset{
+		airports:  make(map[string]domain.Airport, len(records)),
+		locations: make(map[string]*time.Location, len(records)),
+		cities:    make(map[string][]string),
+	}
+	zones := make(map[string]*time.Location)
+	for _, r := range records[1:] {
+		if len(r) != 8 {
+			return nil, fmt.Errorf("expected 8 fields, got %d in %v", len(r), r)
+		}
+		lat, err1 := strconv.ParseFloat(r[5], 64)
+		lon, err2 := strconv.ParseFloat(r[6], 64)
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid coordinates for %s", r[0])
+		}
+
+		loc, ok := zones[r[7]]
+		if !ok {
+			loc, err = time.LoadLocation(r[7])
+			if err != nil {
+				return nil, fmt.Errorf("unknown time zone %q for %s", r[7], r[0])
+			}
+			zones[r[7]] = loc
+		}
+
+		a := domain.Airport{
+			IATA:      r[0],
+			Name:      r[1],
+			City:      r[2],
+			CityCode:  r[3],
+			Country:   r[4],
+			Latitude:  lat,
+			Longitude: lon,
+			Timezone:  r[7],
+		}
+		d.airports[a.IATA] = a
+		d.locations[a.IATA] = loc
+		d.cities[a.CityCode] = append(d.cities[a.CityCode], a.IATA)
+	}
+	for _, codes := range d.cities {
+		sort.Strings(codes)
+	}
+	return d, nil
+}
+
+// Lookup returns the airport with the given IATA code
+func Lookup(code string) (domain.Airport, bool) {
+	a, ok := data().airports[strings.ToUpper(code)]
+	return a, ok
+}
+
+// CityAirports returns the airports serving a city (metropolitan area) code
+func CityAirports(code string) ([]domain.Airport, bool) {
+	codes, ok := data().cities[strings.ToUpper(code)]
+	if !ok {
+		return nil, false
+	}
+	out := make([]domain.Airport, 0, len(codes))
+	for _, c := range codes {
+		out = append(out, data().airports[c])
+	}
+	return out, true
+}
+
+// Normalize upper-cases and trims a location code and checks that it is a
+// known airport or city code. Rejecting the rest up front keeps every code
+// that reaches a provider resolvable to a time zone.
+func Normalize(code string) (string, error) {
+	c := strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := data().airports[c]; ok {
+		return c, nil
+	}
+	if _, ok := data().cities[c]; ok {
+		return c, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrInvalidCode, code)
+}
+
+// Location returns the time zone of the airport, or false for unknown codes
+func Location(iata string) (*time.Location, bool) {
+	loc, ok := data().locations[strings.ToUpper(iata)]
+	return loc, ok
+}
+
+// ParseLocal parses a wall-clock time as shown at the airport, returning
+// ErrUnknownTimezone for airports missing from the reference data
+func ParseLocal(layout, value, iata string) (time.Time, error) {
+	loc, ok := Location(iata)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrUnknownTimezone, iata)
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
+
+// Expand returns the airport codes a location stands for: every airport of a
+// city code, or the airport itself. Codes that name both an airport and the
+// city it serves, such as IST or BKK, stand for the whole city. Unknown codes
+// expand to nothing.
+func Expand(code string) []string {
+	c := strings.ToUpper(code)
+	if codes, ok := data().cities[c]; ok {
+		return append([]string(nil), codes...)
+	}
+	if _, ok := data().airports[c]; ok {
+		return []string{c}
+	}
+	return nil
+}
+
+// Nearby returns the airports within radiusKm of the given airport, closest
+// first, excluding the airport itself
+func Nearby(iata string, radiusKm float64) []domain.Airport {
+	center, ok := Lookup(iata)
+	if !ok || radiusKm <= 0 {
+		return nil
+	}
+
+	type candidate struct {
+		airport domain.Airport
+		km      float64
+	}
+	var found []candidate
+	for _, a := range data().airports {
+		if a.IATA == center.IATA {
+			continue
+		}
+		if km := DistanceKm(center, a); km <= radiusKm {
+			found = append(found, candidate{airport: a, km: km})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].km == found[j].km {
+			return found[i].airport.IATA < found[j].airport.IATA
+		}
+		return found[i].km < found[j].km
+	})
+
+	out := make([]domain.Airport, 0, len(found))
+	for _, c := range found {
+		out = append(out, c.airport)
+	}
+	return out
+}
+
+// DistanceKm returns the great-circle distance between two airports
+func DistanceKm(a, b domain.Airport) float64 {
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(b.Latitude - a.Latitude)
+	dLon := toRad(b.Longitude - a.Longitude)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(a.Latitude))*math.Cos(toRad(b.Latitude))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
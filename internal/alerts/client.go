@@ -0,0 +1,68 @@
+package alerts
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for webhooks pointing at loopback, private,
+// link-local or otherwise non-public addresses
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, RFC 6598
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewWebhookClient returns an HTTP client that refuses to connect to
+// non-public addresses. The check runs on the address actually dialed, so
+// neither DNS names resolving to internal hosts nor redirects get around it.
+func NewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: publicOnly}
+	tr := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     &tls.Config{MinVersion: tls.VersionTLS12},
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &http.Client{Timeout: timeout, Transport: tr}
+}
+
+// CheckWebhookURL rejects URLs that are not http(s) or name a non-public
+// host outright; hosts resolving to one are refused when dialed
+func CheckWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("webhookUrl must be an http(s) URL")
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !public(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicOnly is a net.Dialer Control refusing non-public addresses
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	ap, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("webhook: dial %s: %w", address, err)
+	}
+	if !public(ap.Addr()) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, ap.Addr())
+	}
+	return nil
+}
+
+func public(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,73 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// FileStore is a MemoryStore that rewrites a JSON file after every change, so
+// alerts survive restarts
+type FileStore struct {
+	*MemoryStore
+}
+
+// OpenFileStore loads the alerts saved in path, if any
+func OpenFileStore(path string) (*FileStore, error) {
+	mem := NewMemoryStore()
+
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("alerts: read %s: %w", path, err)
+	default:
+		var saved []domain.Alert
+		if err := json.Unmarshal(raw, &saved); err != nil {
+			return nil, fmt.Errorf("alerts: parse %s: %w", path, err)
+		}
+		for _, a := range saved {
+			mem.alerts[a.ID] = a
+		}
+	}
+
+	mem.onChange = func(all map[string]domain.Alert) error { return save(path, all) }
+	return &FileStore{MemoryStore: mem}, nil
+}
+
+// save writes the alerts to a temporary file and renames it over path, so a
+// crash never leaves a truncated file
+func save(path string, all map[string]domain.Alert) error {
+	list := make([]domain.Alert, 0, len(all))
+	for _, a := range all {
+		list = append(list, a)
+	}
+	slices.SortFunc(list, func(a, b domain.Alert) int { return strings.Compare(a.ID, b.ID) })
+
+	raw, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("alerts: encode: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("alerts: save: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return fmt.Errorf("alerts: save: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("alerts: save: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("alerts: save: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package alerts
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// maxConcurrentChecks bounds the searches run at once by a check round
+const maxConcurrentChecks = 4
+
+// Searcher runs a flight search, flights.Service in production
+type Searcher interface {
+	Search(ctx context.Context, req domain.SearchRequest) (domain.AggregatedResponse, error)
+}
+
+// Scheduler periodically re-runs the search of every active alert and
+// notifies when its cheapest price drops below the threshold. A drop is
+// reported once; a further drop is reported again, and the alert re-arms when
+// the price goes back above the threshold.
+type Scheduler struct {
+	store    Store
+	search   Searcher
+	notifier Notifier
+	interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewScheduler(store Store, search Searcher, notifier Notifier, interval time.Duration) *Scheduler {
+	return &Scheduler{store: store, search: search, notifier: notifier, interval: interval}
+}
+
+// Start checks the alerts every interval until Stop is called
+func (s *Scheduler) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+
+	go func() {
+		defer close(s.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			<-s.stop
+			cancel()
+		}()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunOnce(ctx)
+			}
+		}
+	}()
+}
+
+// Stop ends the checks and waits for the current round to finish
+func (s *Scheduler) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.done
+}
+
+// RunOnce checks every active alert now
+func (s *Scheduler) RunOnce(ctx context.Context) {
+	all, err := s.store.List(ctx, "")
+	if err != nil {
+		log.Printf("✗ Failed to list alerts: %v", err)
+		return
+	}
+
+	sem := make(chan struct{}, maxConcurrentChecks)
+	var wg sync.WaitGroup
+	for _, a := range all {
+		if !a.Active {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(a domain.Alert) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.check(ctx, a)
+		}(a)
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) check(ctx context.Context, a domain.Alert) {
+	req, err := a.Search.Request()
+	if err != nil {
+		log.Printf("✗ Alert %s has an invalid search: %v", a.ID, err)
+		return
+	}
+	req.Currency = a.Below.Currency
+
+	resp, err := s.search.Search(ctx, req)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("✗ Alert %s search failed: %v", a.ID, err)
+			s.update(ctx, a.ID, func(st *domain.Alert) { st.LastCheckedAt = time.Now().UTC() })
+		}
+		return
+	}
+	cheapest := resp.Cheapest
+	now := time.Now().UTC()
+
+	// prices in another currency (no FX rates) can't be compared with the threshold
+	if cheapest == nil || cheapest.Price.Currency != a.Below.Currency {
+		s.update(ctx, a.ID, func(st *domain.Alert) { st.LastCheckedAt = now })
+		return
+	}
+	price := cheapest.Price
+
+	if !price.Less(a.Below) {
+		s.update(ctx, a.ID, func(st *domain.Alert) {
+			st.LastCheckedAt, st.LastPrice, st.NotifiedPrice = now, &price, nil
+		})
+		return
+	}
+	if a.NotifiedPrice != nil && !price.Less(*a.NotifiedPrice) {
+		// this drop was already reported
+		s.update(ctx, a.ID, func(st *domain.Alert) { st.LastCheckedAt, st.LastPrice = now, &price })
+		return
+	}
+
+	n := domain.AlertNotification{
+		Event:      domain.AlertPriceDrop,
+		DeliveryID: NewID(),
+		AlertID:    a.ID,
+		Route:      route(a.Search),
+		Search:     a.Search,
+		Below:      a.Below,
+		Price:      price,
+		Previous:   a.NotifiedPrice,
+		Offer:      *cheapest,
+		SentAt:     now,
+	}
+	if err := s.notifier.Notify(ctx, a, n); err != nil {
+		// not marked as notified, so the next round tries again
+		log.Printf("✗ Alert %s notification failed: %v", a.ID, err)
+		s.update(ctx, a.ID, func(st *domain.Alert) { st.LastCheckedAt, st.LastPrice = now, &price })
+		return
+	}
+	log.Printf("✓ Alert %s notified: %s at %s %s", a.ID, n.Route, price, price.Currency)
+	s.update(ctx, a.ID, func(st *domain.Alert) {
+		st.LastCheckedAt, st.LastPrice, st.NotifiedPrice, st.NotifiedAt = now, &price, &price, now
+	})
+}
+
+func (s *Scheduler) update(ctx context.Context, id string, fn func(*domain.Alert)) {
+	_, err := s.store.Update(ctx, id, func(a *domain.Alert) error {
+		fn(a)
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Printf("✗ Failed to update alert %s: %v", id, err)
+	}
+}
+
+// route formats the alert search as ORIGIN|DESTINATION|YYYY-MM-DD[|YYYY-MM-DD]
+func route(p domain.SearchParams) string {
+	parts := []string{strings.ToUpper(p.Origin), strings.ToUpper(p.Destination), p.StartDate}
+	if p.EndDate != "" {
+		parts = append(parts, p.EndDate)
+	}
+	return strings.Join(parts, "|")
+}
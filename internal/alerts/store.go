@@ -0,0 +1,107 @@
+// Package alerts stores price alerts, checks them periodically and notifies
+// their webhooks when prices drop below the threshold.
+package alerts
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// ErrNotFound is returned for unknown alert IDs
+var ErrNotFound = errors.New("alert not found")
+
+// Store persists alerts
+type Store interface {
+	Create(ctx context.Context, a domain.Alert) error
+	Get(ctx context.Context, id string) (domain.Alert, error)
+	List(ctx context.Context, user string) ([]domain.Alert, error) // every user's when empty
+	// Update applies fn to the stored alert atomically; fn errors abort the update
+	Update(ctx context.Context, id string, fn func(*domain.Alert) error) (domain.Alert, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// NewID returns a random identifier
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MemoryStore keeps alerts in memory
+type MemoryStore struct {
+	mu     sync.RWMutex
+	alerts map[string]domain.Alert
+
+	onChange func(map[string]domain.Alert) error // called with the lock held after every change
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{alerts: make(map[string]domain.Alert)}
+}
+
+func (m *MemoryStore) Create(ctx context.Context, a domain.Alert) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerts[a.ID] = a
+	return m.changed()
+}
+
+func (m *MemoryStore) Get(ctx context.Context, id string) (domain.Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	a, ok := m.alerts[id]
+	if !ok {
+		return domain.Alert{}, ErrNotFound
+	}
+	return a, nil
+}
+
+func (m *MemoryStore) List(ctx context.Context, user string) ([]domain.Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]domain.Alert, 0, len(m.alerts))
+	for _, a := range m.alerts {
+		if user == "" || a.User == user {
+			out = append(out, a)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Alert) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return out, nil
+}
+
+func (m *MemoryStore) Update(ctx context.Context, id string, fn func(*domain.Alert) error) (domain.Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	a, ok := m.alerts[id]
+	if !ok {
+		return domain.Alert{}, ErrNotFound
+	}
+	if err := fn(&a); err != nil {
+		return domain.Alert{}, err
+	}
+	m.alerts[id] = a
+	return a, m.changed()
+}
+
+func (m *MemoryStore) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.alerts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.alerts, id)
+	return m.changed()
+}
+
+func (m *MemoryStore) changed() error {
+	if m.onChange == nil {
+		return nil
+	}
+	return m.onChange(m.alerts)
+}
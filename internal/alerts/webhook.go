@@ -0,0 +1,102 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the request body, keyed with the alert secret
+const SignatureHeader = "X-Signature-256"
+
+// Notifier delivers alert notifications
+type Notifier interface {
+	Notify(ctx context.Context, a domain.Alert, n domain.AlertNotification) error
+}
+
+// Webhook POSTs notifications as signed JSON, retrying network errors, 429s
+// and 5xx responses with exponential backoff
+type Webhook struct {
+	client   *http.Client
+	attempts int
+	backoff  time.Duration // wait before the first retry, doubled after each one
+}
+
+func NewWebhook(client *http.Client, attempts int, backoff time.Duration) *Webhook {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return &Webhook{client: client, attempts: attempts, backoff: backoff}
+}
+
+// Sign returns the signature header value for body, "sha256=<hex>"
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Webhook) Notify(ctx context.Context, a domain.Alert, n domain.AlertNotification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("webhook: encode: %w", err)
+	}
+
+	wait := w.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := w.post(ctx, a, n, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == w.attempts {
+			return fmt.Errorf("webhook: alert %s: %w", a.ID, err)
+		}
+		log.Printf("✗ Webhook for alert %s failed (attempt %d/%d): %v", a.ID, attempt, w.attempts, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// post sends one delivery attempt and reports whether a failure is worth retrying
+func (w *Webhook) post(ctx context.Context, a domain.Alert, n domain.AlertNotification, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(a.Secret, body))
+	req.Header.Set("X-Alert-Event", n.Event)
+	req.Header.Set("X-Alert-Delivery", n.DeliveryID)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil && !errors.Is(err, ErrPrivateAddress), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
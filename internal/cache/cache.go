@@ -0,0 +1,213 @@
+// Package cache provides a generic in-memory cache with per-entry TTL and
+// least-recently-used eviction bounded by entry count and size.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Options bound a cache; zero values mean unbounded
+type Options[V any] struct {
+	MaxEntries int
+	MaxBytes   int64
+	Size       func(V) int64 // estimated size of a value, required by MaxBytes and Stats.Bytes
+}
+
+// Stats are the counters of a cache since it was created
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"` // entries dropped to stay within the bounds
+	Expired   uint64 `json:"expired"`   // entries dropped after their TTL
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+}
+
+// Cache is a TTL cache evicting the least recently used entries first. It is
+// safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	opts Options[V]
+
+	mu    sync.Mutex
+	items map[K]*list.Element
+	lru   *list.List // of *entry[K, V], most recently used first
+	bytes int64
+	stats Stats
+
+	stop chan struct{} // closed by Stop; nil while no cleanup runs
+	done chan struct{}
+}
+
+type entry[K comparable, V any] struct {
+	key  K
+	val  V
+	exp  time.Time
+	size int64
+}
+
+func New[K comparable, V any](opts Options[V]) *Cache[K, V] {
+	return &Cache[K, V]{
+		opts:  opts,
+		items: make(map[K]*list.Element),
+		lru:   list.New(),
+	}
+}
+
+// Get returns the value stored under k, unless it expired
+func (c *Cache[K, V]) Get(k K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.items[k]
+	if !ok {
+		c.stats.Misses++
+		return zero, false
+	}
+	e := el.Value.(*entry[K, V])
+	if time.Now().After(e.exp) {
+		c.remove(el)
+		c.stats.Expired++
+		c.stats.Misses++
+		return zero, false
+	}
+	c.lru.MoveToFront(el)
+	c.stats.Hits++
+	return e.val, true
+}
+
+// Set stores v under k for ttl, evicting the least recently used entries
+// when the cache goes over its bounds. A value bigger than MaxBytes on its
+// own is not stored.
+func (c *Cache[K, V]) Set(k K, v V, ttl time.Duration) {
+	var size int64
+	if c.opts.Size != nil {
+		size = c.opts.Size(v)
+	}
+	if c.opts.MaxBytes > 0 && size > c.opts.MaxBytes {
+		c.Delete(k)
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[k]; ok {
+		c.remove(el)
+	}
+	c.items[k] = c.lru.PushFront(&entry[K, V]{key: k, val: v, exp: time.Now().Add(ttl), size: size})
+	c.bytes += size
+
+	for c.over() {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Delete removes the entry stored under k, if any
+func (c *Cache[K, V]) Delete(k K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[k]; ok {
+		c.remove(el)
+	}
+}
+
+// Clear removes every entry; the counters are kept
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[K]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
+}
+
+// Len returns the number of entries, including expired ones not yet removed
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Stats returns the current counters
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	s.Bytes = c.bytes
+	return s
+}
+
+// StartCleanup starts a goroutine removing expired entries every interval,
+// until Stop is called. It does nothing if the cleanup is already running.
+func (c *Cache[K, V]) StartCleanup(interval time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stop != nil {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	c.stop, c.done = stop, done
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				c.removeExpired()
+			}
+		}
+	}()
+}
+
+// Stop stops the cleanup goroutine and waits for it to exit
+func (c *Cache[K, V]) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop, c.done = nil, nil
+	c.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	close(stop)
+	<-done
+}
+
+func (c *Cache[K, V]) removeExpired() {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.lru.Back(); el != nil; {
+		prev := el.Prev()
+		if now.After(el.Value.(*entry[K, V]).exp) {
+			c.remove(el)
+			c.stats.Expired++
+		}
+		el = prev
+	}
+}
+
+// over reports whether the cache holds more than its bounds allow
+func (c *Cache[K, V]) over() bool {
+	if c.lru.Len() == 0 {
+		return false
+	}
+	return (c.opts.MaxEntries > 0 && c.lru.Len() > c.opts.MaxEntries) ||
+		(c.opts.MaxBytes > 0 && c.bytes > c.opts.MaxBytes)
+}
+
+// remove drops an element; the caller holds the lock
+func (c *Cache[K, V]) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
@@ -0,0 +1,221 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+	"github.com/poportss/go-challenge-flight-price/internal/util"
+)
+
+// Config holds everything the service needs at startup
+type Config struct {
+	Port             string             `json:"port"`
+	JWTSecret        string             `json:"jwt_secret"`
+	SearchTimeout    providers.Duration `json:"search_timeout"`
+	Providers        []providers.Config `json:"providers"`
+	FX               FXConfig           `json:"fx"`
+	HistoryFile      string             `json:"history_file,omitempty"`      // JSON-lines price observations; in memory when empty
+	HistoryRetention providers.Duration `json:"history_retention,omitempty"` // how long observations are kept in the file
+	Alerts           AlertsConfig       `json:"alerts"`
+	Cache            CacheConfig        `json:"cache"`
+	WSOrigins        []string           `json:"ws_allowed_origins,omitempty"` // origins besides the server's own allowed to open WebSockets
+	AirportsFile     string             `json:"airports_file,omitempty"`      // airport reference CSV replacing the embedded one
+}
+
+// CacheConfig bounds the search response cache; zero means unbounded
+type CacheConfig struct {
+	MaxEntries int   `json:"max_entries,omitempty"`
+	MaxBytes   int64 `json:"max_bytes,omitempty"` // approximate, measured on the JSON encoding
+}
+
+// AlertsConfig controls price alert storage and checks
+type AlertsConfig struct {
+	File            string             `json:"file,omitempty"`     // JSON file with the alerts; in memory when empty
+	Interval        providers.Duration `json:"interval,omitempty"` // how often every active alert is checked
+	WebhookAttempts int                `json:"webhook_attempts,omitempty"`
+}
+
+// FXConfig selects where exchange rates come from; with neither a file nor a
+// URL, quotes not in the requested currency are dropped
+type FXConfig struct {
+	RatesFile string             `json:"rates_file,omitempty"`
+	RatesURL  string             `json:"rates_url,omitempty"`
+	TTL       providers.Duration `json:"ttl,omitempty"` // how long rates fetched from the URL are reused
+}
+
+// Load reads the configuration from the JSON file pointed to by CONFIG_FILE,
+// or builds it from environment variables when no file is set. ${VAR}
+// references inside the file's string values are expanded so secrets can stay in
+// the environment.
+func Load() (Config, error) {
+	cfg := fromEnv()
+
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return cfg, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("config: read %s: %w", path, err)
+	}
+
+	// expand the string values only, so a variable can't inject JSON
+	var tree any
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return Config{}, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	expanded, err := json.Marshal(expandEnv(tree))
+	if err != nil {
+		return Config{}, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+	fileCfg := Config{}
+	if err := json.Unmarshal(expanded, &fileCfg); err != nil {
+		return Config{}, fmt.Errorf("config: parse %s: %w", path, err)
+	}
+
+	// values in the file win over environment defaults
+	if fileCfg.Port != "" {
+		cfg.Port = fileCfg.Port
+	}
+	if fileCfg.JWTSecret != "" {
+		cfg.JWTSecret = fileCfg.JWTSecret
+	}
+	if fileCfg.SearchTimeout > 0 {
+		cfg.SearchTimeout = fileCfg.SearchTimeout
+	}
+	if fileCfg.Providers != nil {
+		cfg.Providers = fileCfg.Providers
+	}
+	if fileCfg.FX.RatesFile != "" || fileCfg.FX.RatesURL != "" {
+		cfg.FX = fileCfg.FX
+	}
+	if fileCfg.HistoryFile != "" {
+		cfg.HistoryFile = fileCfg.HistoryFile
+	}
+	if fileCfg.HistoryRetention > 0 {
+		cfg.HistoryRetention = fileCfg.HistoryRetention
+	}
+	if fileCfg.Alerts.File != "" {
+		cfg.Alerts.File = fileCfg.Alerts.File
+	}
+	if fileCfg.Alerts.Interval > 0 {
+		cfg.Alerts.Interval = fileCfg.Alerts.Interval
+	}
+	if fileCfg.Alerts.WebhookAttempts > 0 {
+		cfg.Alerts.WebhookAttempts = fileCfg.Alerts.WebhookAttempts
+	}
+	if fileCfg.Cache.MaxEntries > 0 {
+		cfg.Cache.MaxEntries = fileCfg.Cache.MaxEntries
+	}
+	if fileCfg.Cache.MaxBytes > 0 {
+		cfg.Cache.MaxBytes = fileCfg.Cache.MaxBytes
+	}
+	if fileCfg.WSOrigins != nil {
+		cfg.WSOrigins = fileCfg.WSOrigins
+	}
+	if fileCfg.AirportsFile != "" {
+		cfg.AirportsFile = fileCfg.AirportsFile
+	}
+	return cfg, nil
+}
+
+// expandEnv replaces ${VAR} references in every string of a decoded JSON value
+func expandEnv(v any) any {
+	switch v := v.(type) {
+	case string:
+		return os.ExpandEnv(v)
+	case []any:
+		for i := range v {
+			v[i] = expandEnv(v[i])
+		}
+	case map[string]any:
+		for k := range v {
+			v[k] = expandEnv(v[k])
+		}
+	}
+	return v
+}
+
+// fromEnv enables each real provider only when its credentials are present
+func fromEnv() Config {
+	amadeusID := os.Getenv("AMADEUS_CLIENT_ID")
+	amadeusSecret := os.Getenv("AMADEUS_CLIENT_SECRET")
+	serpKey := os.Getenv("SERP_API_GOOGLEFLIGHTS_API_KEY")
+
+	return Config{
+		Port:          util.EnvOr("PORT", "8080"),
+		JWTSecret:     util.EnvOr("JWT_SECRET", "devsecret"),
+		SearchTimeout: providers.Duration(1 * time.Minute),
+		Providers: []providers.Config{
+			{
+				Type:         "amadeus",
+				Enabled:      amadeusID != "" && amadeusSecret != "",
+				BaseURL:      util.EnvOr("AMADEUS_BASE_URL", providers.DefaultAmadeusBaseURL),
+				ClientID:     amadeusID,
+				ClientSecret: amadeusSecret,
+			},
+			{
+				Type:    "googleflights",
+				Enabled: serpKey != "",
+				BaseURL: util.EnvOr("SERP_API_GOOGLEFLIGHTS_BASE_URL", util.EnvOr("GOOGLE_FLIGHTS_BASE_URL", providers.DefaultGoogleFlightsBaseURL)),
+				APIKey:  serpKey,
+			},
+			{
+				Type:    "mock",
+				Name:    util.EnvOr("MOCK_PROVIDER_NAME", "Ports Airlines"),
+				Enabled: !strings.EqualFold(os.Getenv("MOCK_PROVIDER_ENABLED"), "false"),
+			},
+		},
+		FX: FXConfig{
+			RatesFile: os.Getenv("FX_RATES_FILE"),
+			RatesURL:  os.Getenv("FX_RATES_URL"),
+			TTL:       providers.Duration(1 * time.Hour),
+		},
+		HistoryFile:      os.Getenv("HISTORY_FILE"),
+		HistoryRetention: providers.Duration(envDuration("HISTORY_RETENTION", 2*365*24*time.Hour)),
+		Alerts: AlertsConfig{
+			File:            os.Getenv("ALERTS_FILE"),
+			Interval:        providers.Duration(envDuration("ALERTS_INTERVAL", 15*time.Minute)),
+			WebhookAttempts: 4,
+		},
+		Cache: CacheConfig{
+			MaxEntries: int(envInt("CACHE_MAX_ENTRIES", 10_000)),
+			MaxBytes:   envInt("CACHE_MAX_BYTES", 64<<20),
+		},
+		WSOrigins:    envList("WS_ALLOWED_ORIGINS"),
+		AirportsFile: os.Getenv("AIRPORTS_FILE"),
+	}
+}
+
+// envDuration reads a duration such as "15m" from the environment
+func envDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(key)); err == nil && d > 0 {
+		return d
+	}
+	return def
+}
+
+// envInt reads a positive integer from the environment
+func envInt(key string, def int64) int64 {
+	if n, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// envList reads a comma-separated list from the environment
+func envList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
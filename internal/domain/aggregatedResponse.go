@@ -1,7 +1,11 @@
 package domain
 
 type AggregatedResponse struct {
-	Cheapest *Quote  `json:"cheapest"`
-	Fastest  *Quote  `json:"fastest"`
-	Offers   []Quote `json:"offers"` // order by price, after by duration
+	Cheapest     *Quote             `json:"cheapest"`
+	Fastest      *Quote             `json:"fastest"`
+	Offers       []Quote            `json:"offers"`                  // order by price, after by duration
+	Airports     map[string]Airport `json:"airports,omitempty"`      // reference data for every airport in the response
+	Providers    []ProviderStatus   `json:"providers"`               // outcome of every provider consulted
+	Partial      bool               `json:"partial"`                 // true when a provider failed or timed out
+	SkippedPairs int                `json:"skipped_pairs,omitempty"` // airport pairs left out of a metro-area or nearby search over the limit
 }
@@ -0,0 +1,13 @@
+package domain
+
+// Airport is the reference information about an airport
+type Airport struct {
+	IATA      string  `json:"iata"`
+	Name      string  `json:"name"`
+	City      string  `json:"city"`
+	CityCode  string  `json:"city_code"` // IATA metropolitan area code, e.g. NYC for JFK
+	Country   string  `json:"country"`   // ISO 3166-1 alpha-2
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Timezone  string  `json:"timezone"` // IANA zone name
+}
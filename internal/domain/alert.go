@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Alert watches a search and notifies a webhook when its cheapest price drops
+// below a threshold
+type Alert struct {
+	ID            string       `json:"id"`
+	User          string       `json:"user"`
+	Search        SearchParams `json:"search"`
+	Below         Money        `json:"below"` // threshold; the search runs in its currency
+	WebhookURL    string       `json:"webhookUrl"`
+	Secret        string       `json:"secret,omitempty"` // HMAC key for webhook signatures, only shown on creation
+	Active        bool         `json:"active"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	LastCheckedAt time.Time    `json:"lastCheckedAt,omitzero"`
+	LastPrice     *Money       `json:"lastPrice,omitempty"`     // cheapest price at the last check
+	NotifiedPrice *Money       `json:"notifiedPrice,omitempty"` // last price reported, cleared once the price is back above the threshold
+	NotifiedAt    time.Time    `json:"notifiedAt,omitzero"`
+}
+
+// AlertRequest is the JSON body that creates an alert
+type AlertRequest struct {
+	Search     SearchParams `json:"search"`
+	Below      Money        `json:"below"`
+	WebhookURL string       `json:"webhookUrl" binding:"required,url"`
+	Secret     string       `json:"secret" binding:"omitempty,min=16"` // generated when empty
+}
+
+// AlertUpdate is the JSON body that changes an alert; absent fields are kept
+type AlertUpdate struct {
+	Active     *bool   `json:"active"`
+	Below      *Money  `json:"below"`
+	WebhookURL *string `json:"webhookUrl" binding:"omitempty,url"`
+}
+
+// AlertNotification is the webhook payload sent when an alert fires
+type AlertNotification struct {
+	Event      string       `json:"event"`
+	DeliveryID string       `json:"deliveryId"` // the same across retries of one notification
+	AlertID    string       `json:"alertId"`
+	Route      string       `json:"route"`
+	Search     SearchParams `json:"search"`
+	Below      Money        `json:"below"`
+	Price      Money        `json:"price"`
+	Previous   *Money       `json:"previousPrice,omitempty"` // last price reported, when this is a further drop
+	Offer      Quote        `json:"offer"`
+	SentAt     time.Time    `json:"sentAt"`
+}
+
+// AlertPriceDrop is the event of AlertNotification
+const AlertPriceDrop = "price.below_threshold"
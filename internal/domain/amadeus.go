@@ -8,42 +8,16 @@ type AmadeusResponse struct {
 		} `json:"links"`
 	} `json:"meta"`
 	Data []struct {
-		Type                     string `json:"type"`
-		Id                       string `json:"id"`
-		Source                   string `json:"source"`
-		InstantTicketingRequired bool   `json:"instantTicketingRequired"`
-		NonHomogeneous           bool   `json:"nonHomogeneous"`
-		OneWay                   bool   `json:"oneWay"`
-		LastTicketingDate        string `json:"lastTicketingDate"`
-		NumberOfBookableSeats    int    `json:"numberOfBookableSeats"`
-		Itineraries              []struct {
-			Duration string `json:"duration"`
-			Segments []struct {
-				Departure struct {
-					IataCode string `json:"iataCode"`
-					Terminal string `json:"terminal"`
-					At       string `json:"at"`
-				} `json:"departure"`
-				Arrival struct {
-					IataCode string `json:"iataCode"`
-					Terminal string `json:"terminal,omitempty"`
-					At       string `json:"at"`
-				} `json:"arrival"`
-				CarrierCode string `json:"carrierCode"`
-				Number      string `json:"number"`
-				Aircraft    struct {
-					Code string `json:"code"`
-				} `json:"aircraft"`
-				Operating struct {
-					CarrierCode string `json:"carrierCode"`
-				} `json:"operating"`
-				Duration        string `json:"duration"`
-				Id              string `json:"id"`
-				NumberOfStops   int    `json:"numberOfStops"`
-				BlacklistedInEU bool   `json:"blacklistedInEU"`
-			} `json:"segments"`
-		} `json:"itineraries"`
-		Price struct {
+		Type                     string             `json:"type"`
+		Id                       string             `json:"id"`
+		Source                   string             `json:"source"`
+		InstantTicketingRequired bool               `json:"instantTicketingRequired"`
+		NonHomogeneous           bool               `json:"nonHomogeneous"`
+		OneWay                   bool               `json:"oneWay"`
+		LastTicketingDate        string             `json:"lastTicketingDate"`
+		NumberOfBookableSeats    int                `json:"numberOfBookableSeats"`
+		Itineraries              []AmadeusItinerary `json:"itineraries"`
+		Price                    struct {
 			Currency string `json:"currency"`
 			Total    string `json:"total"`
 			Base     string `json:"base"`
@@ -107,3 +81,67 @@ type AmadeusResponse struct {
 		} `json:"carriers"`
 	} `json:"dictionaries"`
 }
+
+type AmadeusItinerary struct {
+	Duration string           `json:"duration"`
+	Segments []AmadeusSegment `json:"segments"`
+}
+
+type AmadeusSegment struct {
+	Departure struct {
+		IataCode string `json:"iataCode"`
+		Terminal string `json:"terminal"`
+		At       string `json:"at"`
+	} `json:"departure"`
+	Arrival struct {
+		IataCode string `json:"iataCode"`
+		Terminal string `json:"terminal,omitempty"`
+		At       string `json:"at"`
+	} `json:"arrival"`
+	CarrierCode string `json:"carrierCode"`
+	Number      string `json:"number"`
+	Aircraft    struct {
+		Code string `json:"code"`
+	} `json:"aircraft"`
+	Operating struct {
+		CarrierCode string `json:"carrierCode"`
+	} `json:"operating"`
+	Duration        string `json:"duration"`
+	Id              string `json:"id"`
+	NumberOfStops   int    `json:"numberOfStops"`
+	BlacklistedInEU bool   `json:"blacklistedInEU"`
+}
+
+// AmadeusFlightDatesResponse is the Flight Cheapest Date Search response
+type AmadeusFlightDatesResponse struct {
+	Meta struct {
+		Currency string `json:"currency"`
+	} `json:"meta"`
+	Data []struct {
+		Type          string `json:"type"`
+		Origin        string `json:"origin"`
+		Destination   string `json:"destination"`
+		DepartureDate string `json:"departureDate"`
+		ReturnDate    string `json:"returnDate"`
+		Price         struct {
+			Total string `json:"total"`
+		} `json:"price"`
+	} `json:"data"`
+}
+
+// AmadeusFlightDestinationsResponse is the Flight Inspiration Search response
+type AmadeusFlightDestinationsResponse struct {
+	Meta struct {
+		Currency string `json:"currency"`
+	} `json:"meta"`
+	Data []struct {
+		Type          string `json:"type"`
+		Origin        string `json:"origin"`
+		Destination   string `json:"destination"`
+		DepartureDate string `json:"departureDate"`
+		ReturnDate    string `json:"returnDate"`
+		Price         struct {
+			Total string `json:"total"`
+		} `json:"price"`
+	} `json:"data"`
+}
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AnywhereRequest asks where an origin can fly within a budget, e.g. anywhere
+// from GRU in December for less than 600 USD
+type AnywhereRequest struct {
+	Origin   string    `form:"origin" binding:"required,len=3"`
+	From     time.Time `form:"from" time_format:"2006-01-02" binding:"required"`
+	To       time.Time `form:"to" time_format:"2006-01-02" binding:"required,gtefield=From"`
+	MaxPrice string    `form:"maxPrice" binding:"required"` // in Currency, e.g. "600" or "599.90"
+	Currency string    `form:"currency" binding:"omitempty,len=3"`
+	MinDays  int       `form:"minDays" binding:"omitempty,min=1,max=30"` // trip length, ignored for one-way
+	MaxDays  int       `form:"maxDays" binding:"omitempty,min=1,max=30"`
+	OneWay   bool      `form:"oneWay"`
+	NonStop  bool      `form:"nonStop"`
+	Limit    int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// AnywhereQuery is the provider-agnostic description of a destination search
+type AnywhereQuery struct {
+	Origin   string
+	From, To time.Time // departure window, both included
+	MinDays  int       // trip length bounds, zero for one-way
+	MaxDays  int
+	OneWay   bool
+	NonStop  bool
+	MaxPrice Money // the budget; providers may use it to narrow their results
+	Limit    int   // maximum number of destinations returned
+}
+
+// Query converts the HTTP request into an AnywhereQuery, applying defaults
+func (r AnywhereRequest) Query() (AnywhereQuery, error) {
+	currency := strings.ToUpper(r.Currency)
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+	budget, err := ParseMoney(r.MaxPrice, currency)
+	if err != nil {
+		return AnywhereQuery{}, fmt.Errorf("maxPrice: %w", err)
+	}
+
+	q := AnywhereQuery{
+		Origin:   strings.ToUpper(r.Origin),
+		From:     r.From,
+		To:       r.To,
+		OneWay:   r.OneWay,
+		NonStop:  r.NonStop,
+		MaxPrice: budget,
+		Limit:    r.Limit,
+	}
+	if !q.OneWay {
+		q.MinDays, q.MaxDays = tripDays(r.MinDays, r.MaxDays)
+	}
+	if q.Limit == 0 {
+		q.Limit = DefaultExploreLimit
+	}
+	return q, nil
+}
+
+// DestinationPrice is the cheapest price found to fly to a destination
+type DestinationPrice struct {
+	Destination   string `json:"destination"` // airport or city code
+	Name          string `json:"name,omitempty"`
+	City          string `json:"city,omitempty"`
+	Country       string `json:"country,omitempty"`
+	DepartureDate string `json:"departureDate"`
+	ReturnDate    string `json:"returnDate,omitempty"`
+	Price         Money  `json:"price"`
+	Provider      string `json:"provider"`
+}
+
+// AnywhereResponse lists the destinations reachable within the budget, cheapest first
+type AnywhereResponse struct {
+	Origin       string             `json:"origin"`
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	MaxPrice     Money              `json:"maxPrice"`
+	Destinations []DestinationPrice `json:"destinations"`
+}
@@ -0,0 +1,34 @@
+package domain
+
+// MaxCalendarFlexDays bounds the ± day window of a price calendar
+const MaxCalendarFlexDays = 3
+
+// CalendarRequest is a search whose dates may move by up to DepartFlex and
+// ReturnFlex days either way
+type CalendarRequest struct {
+	SearchRequest
+	DepartFlex int `form:"departFlex" binding:"omitempty,min=0,max=3"`
+	ReturnFlex int `form:"returnFlex" binding:"omitempty,min=0,max=3"` // ignored for one-way searches
+}
+
+// CalendarCell is the cheapest offer for one departure/return combination
+type CalendarCell struct {
+	DepartureDate string `json:"departureDate"`
+	ReturnDate    string `json:"returnDate,omitempty"`
+	Price         *Money `json:"price,omitempty"` // nil when nothing was found
+	Provider      string `json:"provider,omitempty"`
+	Airline       string `json:"airline,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Cheapest      bool   `json:"cheapest,omitempty"` // the cheapest cell of the calendar
+}
+
+// PriceCalendar is a departure × return matrix of cheapest prices
+type PriceCalendar struct {
+	Origin         string           `json:"origin"`
+	Destination    string           `json:"destination"`
+	DepartureDates []string         `json:"departureDates"`
+	ReturnDates    []string         `json:"returnDates,omitempty"` // empty for one-way
+	Cells          [][]CalendarCell `json:"cells"`                 // [departure][return], a single column for one-way
+	Cheapest       *CalendarCell    `json:"cheapest,omitempty"`
+	SkippedPairs   int              `json:"skippedPairs,omitempty"` // airport pairs left out of every search over the limit
+}
@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Where an explorer price comes from
+const (
+	SourceLive       = "live"       // quoted by a provider for this request
+	SourceHistorical = "historical" // estimated from stored price observations
+)
+
+const (
+	DefaultTripDays     = 7
+	DefaultExploreLimit = 10
+)
+
+// ExploreRequest asks for the cheapest dates to fly a route within a window,
+// e.g. departing between March and June for a 10–14 day trip
+type ExploreRequest struct {
+	Origin      string    `form:"origin" binding:"required,len=3"`
+	Destination string    `form:"destination" binding:"required,len=3"`
+	From        time.Time `form:"from" time_format:"2006-01-02" binding:"required"`
+	To          time.Time `form:"to" time_format:"2006-01-02" binding:"required,gtefield=From"`
+	MinDays     int       `form:"minDays" binding:"omitempty,min=1,max=30"` // trip length, ignored for one-way
+	MaxDays     int       `form:"maxDays" binding:"omitempty,min=1,max=30"`
+	OneWay      bool      `form:"oneWay"`
+	NonStop     bool      `form:"nonStop"`
+	Currency    string    `form:"currency" binding:"omitempty,len=3"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// CheapestDateQuery is the provider-agnostic description of a cheapest-date search
+type CheapestDateQuery struct {
+	Origin      string
+	Destination string
+	From, To    time.Time // departure window, both included
+	MinDays     int       // trip length bounds, zero for one-way
+	MaxDays     int
+	OneWay      bool
+	NonStop     bool
+	Currency    string
+}
+
+// Query converts the HTTP request into a CheapestDateQuery, applying defaults
+func (r ExploreRequest) Query() CheapestDateQuery {
+	q := CheapestDateQuery{
+		Origin:      strings.ToUpper(r.Origin),
+		Destination: strings.ToUpper(r.Destination),
+		From:        r.From,
+		To:          r.To,
+		OneWay:      r.OneWay,
+		NonStop:     r.NonStop,
+		Currency:    strings.ToUpper(r.Currency),
+	}
+	if q.Currency == "" {
+		q.Currency = DefaultCurrency
+	}
+	if !q.OneWay {
+		q.MinDays, q.MaxDays = tripDays(r.MinDays, r.MaxDays)
+	}
+	return q
+}
+
+// tripDays applies the trip length defaults: a single bound is used for both
+// ends, none means DefaultTripDays
+func tripDays(minDays, maxDays int) (int, int) {
+	if minDays == 0 && maxDays == 0 {
+		return DefaultTripDays, DefaultTripDays
+	}
+	if minDays == 0 {
+		minDays = maxDays
+	}
+	if maxDays == 0 {
+		maxDays = minDays
+	}
+	return minDays, maxDays
+}
+
+// DatePrice is the cheapest price found for one departure/return pair
+type DatePrice struct {
+	DepartureDate string `json:"departureDate"`
+	ReturnDate    string `json:"returnDate,omitempty"`
+	Price         Money  `json:"price"`
+	Provider      string `json:"provider,omitempty"`
+	Airline       string `json:"airline,omitempty"`
+	Source        string `json:"source"` // SourceLive or SourceHistorical
+}
+
+// ExploreResponse ranks the date pairs of a route by price
+type ExploreResponse struct {
+	Origin      string      `json:"origin"`
+	Destination string      `json:"destination"`
+	From        string      `json:"from"`
+	To          string      `json:"to"`
+	MinDays     int         `json:"minDays,omitempty"`
+	MaxDays     int         `json:"maxDays,omitempty"`
+	Currency    string      `json:"currency"`
+	Results     []DatePrice `json:"results"`
+}
@@ -1,37 +1,43 @@
 package domain
 
+import "encoding/json"
+
 type GoogleFlightsResponse struct {
-	BestFlights []struct {
-		Flights []struct {
-			DepartureAirport struct {
-				Name string `json:"name"`
-				ID   string `json:"id"`
-				Time string `json:"time"`
-			} `json:"departure_airport"`
-			ArrivalAirport struct {
-				Name string `json:"name"`
-				ID   string `json:"id"`
-				Time string `json:"time"`
-			} `json:"arrival_airport"`
-			Duration int    `json:"duration"`
-			Airline  string `json:"airline"`
-			Price    float64
-		} `json:"flights"`
-		Price float64 `json:"price"`
-	} `json:"best_flights"`
-	OtherFlights []struct {
-		Flights []struct {
-			DepartureAirport struct {
-				ID   string `json:"id"`
-				Time string `json:"time"`
-			} `json:"departure_airport"`
-			ArrivalAirport struct {
-				ID   string `json:"id"`
-				Time string `json:"time"`
-			} `json:"arrival_airport"`
-			Duration int    `json:"duration"`
-			Airline  string `json:"airline"`
-		} `json:"flights"`
-		Price float64 `json:"price"`
-	} `json:"other_flights"`
+	BestFlights  []GoogleFlightsOption `json:"best_flights"`
+	OtherFlights []GoogleFlightsOption `json:"other_flights"`
+}
+
+type GoogleFlightsOption struct {
+	Flights       []GoogleFlightsSegment `json:"flights"`
+	Layovers      []GoogleFlightsLayover `json:"layovers"`
+	TotalDuration int                    `json:"total_duration"`
+	Price         json.Number            `json:"price"`
+	Type          string                 `json:"type"`
+}
+
+type GoogleFlightsSegment struct {
+	DepartureAirport struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+		Time string `json:"time"`
+	} `json:"departure_airport"`
+	ArrivalAirport struct {
+		Name string `json:"name"`
+		ID   string `json:"id"`
+		Time string `json:"time"`
+	} `json:"arrival_airport"`
+	Duration       int    `json:"duration"`
+	Airplane       string `json:"airplane"`
+	Airline        string `json:"airline"`
+	FlightNumber   string `json:"flight_number"`
+	PlaneAndCrewBy string `json:"plane_and_crew_by"`
+	TravelClass    string `json:"travel_class"`
+	Overnight      bool   `json:"overnight"`
+}
+
+type GoogleFlightsLayover struct {
+	Duration  int    `json:"duration"`
+	Name      string `json:"name"`
+	ID        string `json:"id"`
+	Overnight bool   `json:"overnight"`
 }
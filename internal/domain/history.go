@@ -0,0 +1,33 @@
+package domain
+
+// Dates a price history can be grouped by
+const (
+	HistoryByDeparture   = "departure" // the month the flights leave
+	HistoryByObservation = "observed"  // the month the prices were seen
+)
+
+// HistoryRequest selects the route and months of a price history, for one
+// traveler in economy unless Passengers and Cabin say otherwise
+type HistoryRequest struct {
+	Origin      string `form:"origin" binding:"required,len=3"`
+	Destination string `form:"destination" binding:"required,len=3"`
+	GroupBy     string `form:"groupBy" binding:"omitempty,oneof=departure observed"` // defaults to departure
+	From        string `form:"from" binding:"omitempty,datetime=2006-01"`            // first month, defaults to a year-long period
+	To          string `form:"to" binding:"omitempty,datetime=2006-01"`              // last month
+	Currency    string `form:"currency" binding:"omitempty,len=3"`
+	Cabin       string `form:"cabin" binding:"omitempty,oneof=ECONOMY PREMIUM_ECONOMY BUSINESS FIRST economy premium_economy business first"`
+	Passengers  int    `form:"passengers" binding:"omitempty,min=1,max=9"`
+}
+
+// PriceHistory is the monthly price history of a route
+type PriceHistory struct {
+	Origin      string         `json:"origin"`
+	Destination string         `json:"destination"`
+	GroupBy     string         `json:"groupBy"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Cabin       string         `json:"cabin"`
+	Passengers  int            `json:"passengers"`
+	Currency    string         `json:"currency"`
+	History     []MonthlyPrice `json:"history"` // months without observations are omitted
+}
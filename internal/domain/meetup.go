@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How meet-up destinations are ranked
+const (
+	RankByPrice    = "price"    // combined cheapest fare of every attendee
+	RankByDuration = "duration" // longest travel time among attendees
+)
+
+// MeetupRequest is the JSON body of a meet-up search: where should people
+// flying from these origins meet?
+type MeetupRequest struct {
+	Origins       []string `json:"origins" binding:"required,min=2,max=10,dive,len=3"` // one per attendee, repeated when several leave from the same place
+	Destinations  []string `json:"destinations" binding:"required,min=1,max=20,dive,len=3"`
+	DepartureDate string   `json:"departureDate" binding:"required,datetime=2006-01-02"`
+	ReturnDate    string   `json:"returnDate" binding:"omitempty,datetime=2006-01-02"` // empty for one-way
+	RankBy        string   `json:"rankBy" binding:"omitempty,oneof=price duration"`
+	Cabin         string   `json:"cabin" binding:"omitempty,oneof=ECONOMY PREMIUM_ECONOMY BUSINESS FIRST economy premium_economy business first"`
+	NonStop       bool     `json:"nonStop"`
+	Currency      string   `json:"currency" binding:"omitempty,len=3"`
+}
+
+// MeetupQuery is a validated meet-up search
+type MeetupQuery struct {
+	Origins       []string
+	Destinations  []string
+	DepartureDate time.Time
+	ReturnDate    time.Time // zero for one-way trips
+	RankBy        string
+	Cabin         string
+	NonStop       bool
+	Currency      string
+}
+
+// Query parses the dates and applies defaults
+func (r MeetupRequest) Query() (MeetupQuery, error) {
+	dep, err := time.Parse("2006-01-02", r.DepartureDate)
+	if err != nil {
+		return MeetupQuery{}, fmt.Errorf("departureDate: %w", err)
+	}
+	var ret time.Time
+	if r.ReturnDate != "" {
+		if ret, err = time.Parse("2006-01-02", r.ReturnDate); err != nil {
+			return MeetupQuery{}, fmt.Errorf("returnDate: %w", err)
+		}
+		if ret.Before(dep) {
+			return MeetupQuery{}, fmt.Errorf("returnDate %s is before departureDate %s", r.ReturnDate, r.DepartureDate)
+		}
+	}
+
+	q := MeetupQuery{
+		Origins:       upper(r.Origins),
+		Destinations:  upper(r.Destinations),
+		DepartureDate: dep,
+		ReturnDate:    ret,
+		RankBy:        strings.ToLower(r.RankBy),
+		Cabin:         strings.ToUpper(r.Cabin),
+		NonStop:       r.NonStop,
+		Currency:      strings.ToUpper(r.Currency),
+	}
+	if q.RankBy == "" {
+		q.RankBy = RankByPrice
+	}
+	if q.Currency == "" {
+		q.Currency = DefaultCurrency
+	}
+	return q, nil
+}
+
+func upper(codes []string) []string {
+	out := make([]string, len(codes))
+	for i, c := range codes {
+		out[i] = strings.ToUpper(c)
+	}
+	return out
+}
+
+// AttendeeQuote is the best quote from one origin to a meet-up destination
+type AttendeeQuote struct {
+	Origin string `json:"origin"`
+	Local  bool   `json:"local,omitempty"` // the origin is at the destination, nothing to fly
+	Quote  *Quote `json:"quote,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// MeetupOption is one candidate destination with every attendee's best quote
+type MeetupOption struct {
+	Destination string          `json:"destination"`
+	City        string          `json:"city,omitempty"`
+	Country     string          `json:"country,omitempty"`
+	Complete    bool            `json:"complete"`             // every attendee can get there
+	TotalPrice  *Money          `json:"totalPrice,omitempty"` // set when complete
+	MaxDuration time.Duration   `json:"maxDuration"`          // longest outbound travel time
+	Attendees   []AttendeeQuote `json:"attendees"`            // in the order of the request's origins
+}
+
+// MeetupResponse ranks the candidate destinations, best first; destinations
+// some attendee can't reach come last
+type MeetupResponse struct {
+	Origins      []string       `json:"origins"`
+	RankBy       string         `json:"rankBy"`
+	Currency     string         `json:"currency"`
+	Destinations []MeetupOption `json:"destinations"`
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidAmount is returned when a price can't be parsed exactly
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrCurrencyMismatch is returned when combining amounts in different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrInvalidRate is returned when converting with a rate that isn't a finite
+// positive number, or that takes the amount out of range
+var ErrInvalidRate = errors.New("invalid exchange rate")
+
+// minorDigits lists the ISO 4217 currencies whose minor unit isn't the cent
+var minorDigits = map[string]int{
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// MinorDigits returns the number of decimals used by the currency
+func MinorDigits(currency string) int {
+	if d, ok := minorDigits[strings.ToUpper(currency)]; ok {
+		return d
+	}
+	return 2
+}
+
+// Money is an exact amount in the currency's minor unit (e.g. cents)
+type Money struct {
+	Minor    int64  // amount in minor units
+	Currency string // ISO 4217
+}
+
+// NewMoney builds an amount from minor units
+func NewMoney(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney parses a decimal string such as "812.40", rejecting anything that
+// isn't an exact, non-negative amount in the currency's precision
+func ParseMoney(amount, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if len(currency) != 3 {
+		return Money{}, fmt.Errorf("%w: currency %q", ErrInvalidAmount, currency)
+	}
+
+	s := strings.TrimSpace(amount)
+	whole, frac, hasDot := strings.Cut(s, ".")
+	if whole == "" || (hasDot && frac == "") || strings.ContainsAny(whole, "+-") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+
+	digits := MinorDigits(currency)
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > digits {
+		return Money{}, fmt.Errorf("%w: %q has more than %d decimals", ErrInvalidAmount, amount, digits)
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// MoneyFromFloat rounds a float amount to the nearest minor unit; only meant
+// for values computed in-process, never for parsing provider prices
+func MoneyFromFloat(amount float64, currency string) Money {
+	scale := math.Pow10(MinorDigits(currency))
+	return NewMoney(int64(math.Round(amount*scale)), currency)
+}
+
+// String formats the amount with the currency's decimals, e.g. "812.40"
+func (m Money) String() string {
+	digits := MinorDigits(m.Currency)
+	if digits == 0 {
+		return strconv.FormatInt(m.Minor, 10)
+	}
+	sign := ""
+	minor := m.Minor
+	if minor < 0 {
+		sign, minor = "-", -minor
+	}
+	scale := int64(math.Pow10(digits))
+	return fmt.Sprintf("%s%d.%0*d", sign, minor/scale, digits, minor%scale)
+}
+
+// Float returns the approximate amount, for display and cross-currency ordering only
+func (m Money) Float() float64 {
+	return float64(m.Minor) / math.Pow10(MinorDigits(m.Currency))
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+// Add sums two amounts in the same currency
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Minor: m.Minor + o.Minor, Currency: m.Currency}, nil
+}
+
+// Sub subtracts an amount in the same currency
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return Money{Minor: m.Minor - o.Minor, Currency: m.Currency}, nil
+}
+
+// Mul multiplies the amount, e.g. by a number of passengers
+func (m Money) Mul(n int64) Money {
+	return Money{Minor: m.Minor * n, Currency: m.Currency}
+}
+
+// Markup adds a percentage expressed in basis points (150 = 1.5%), rounding half up
+func (m Money) Markup(bps int64) Money {
+	return Money{Minor: m.Minor + RoundDiv(m.Minor*bps, 10_000), Currency: m.Currency}
+}
+
+// Convert applies an exchange rate and rounds to the target currency's minor unit
+func (m Money) Convert(rate float64, to string) (Money, error) {
+	to = strings.ToUpper(to)
+	if to == m.Currency {
+		return m, nil
+	}
+	if math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+		return Money{}, fmt.Errorf("%w: %v %s to %s", ErrInvalidRate, rate, m.Currency, to)
+	}
+	// exact decimal arithmetic so large amounts don't pick up float error
+	amount := new(big.Rat).SetFrac64(m.Minor, int64(math.Pow10(MinorDigits(m.Currency))))
+	amount.Mul(amount, new(big.Rat).SetFloat64(rate))
+	amount.Mul(amount, new(big.Rat).SetInt64(int64(math.Pow10(MinorDigits(to)))))
+
+	q, rem := new(big.Int).QuoRem(amount.Num(), amount.Denom(), new(big.Int))
+	// round half away from zero
+	if new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(amount.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(amount.Sign())))
+	}
+	if !q.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %v %s to %s overflows", ErrInvalidRate, rate, m.Currency, to)
+	}
+	return Money{Minor: q.Int64(), Currency: to}, nil
+}
+
+// Less orders amounts; amounts in different currencies are compared approximately
+func (m Money) Less(o Money) bool {
+	if m.Currency == o.Currency {
+		return m.Minor < o.Minor
+	}
+	return m.Float() < o.Float()
+}
+
+// MarshalJSON encodes the amount as {"amount":"812.40","currency":"USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{m.String(), m.Currency})
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Amount   json.Number `json:"amount"`
+		Currency string      `json:"currency"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	v, err := ParseMoney(raw.Amount.String(), raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// RoundDiv divides minor amounts rounding half away from zero
+func RoundDiv(a, b int64) int64 {
+	if (a < 0) != (b < 0) {
+		return (a - b/2) / b
+	}
+	return (a + b/2) / b
+}
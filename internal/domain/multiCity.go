@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxMultiCityLegs = 6
+
+// LegRequest is one origin/destination pair of a multi-city search
+type LegRequest struct {
+	Origin      string `json:"origin" binding:"required,len=3"`
+	Destination string `json:"destination" binding:"required,len=3"`
+	Date        string `json:"date" binding:"required,datetime=2006-01-02"`
+}
+
+// MultiCityRequest is the JSON body of a multi-city (open-jaw) search
+type MultiCityRequest struct {
+	Legs       []LegRequest `json:"legs" binding:"required,min=2,max=6,dive"`
+	Adults     int          `json:"adults" binding:"omitempty,min=1,max=9"`
+	Children   int          `json:"children" binding:"omitempty,min=0,max=9"`
+	Infants    int          `json:"infants" binding:"omitempty,min=0,max=9"`
+	Cabin      string       `json:"cabin" binding:"omitempty,oneof=ECONOMY PREMIUM_ECONOMY BUSINESS FIRST economy premium_economy business first"`
+	NonStop    bool         `json:"nonStop"`
+	MaxResults int          `json:"max" binding:"omitempty,min=1,max=250"`
+	Currency   string       `json:"currency" binding:"omitempty,len=3"`
+}
+
+// Leg is one flown origin/destination pair on a given date
+type Leg struct {
+	Origin      string
+	Destination string
+	Date        time.Time
+}
+
+// MultiCityQuery is the provider-agnostic description of a multi-city search
+type MultiCityQuery struct {
+	Legs []Leg
+	SearchOptions
+}
+
+// Key uniquely identifies the query, e.g. for caching
+func (q MultiCityQuery) Key() string {
+	var b strings.Builder
+	b.WriteString("multi")
+	for _, l := range q.Legs {
+		fmt.Fprintf(&b, "|%s-%s@%s", l.Origin, l.Destination, l.Date.Format("2006-01-02"))
+	}
+	b.WriteString("|")
+	b.WriteString(q.SearchOptions.key())
+	return b.String()
+}
+
+// Query converts the HTTP request into a MultiCityQuery, applying defaults and
+// checking that the legs are in chronological order
+func (r MultiCityRequest) Query() (MultiCityQuery, error) {
+	if len(r.Legs) < 2 || len(r.Legs) > MaxMultiCityLegs {
+		return MultiCityQuery{}, fmt.Errorf("a multi-city search needs between 2 and %d legs", MaxMultiCityLegs)
+	}
+
+	q := MultiCityQuery{
+		Legs: make([]Leg, 0, len(r.Legs)),
+		SearchOptions: SearchOptions{
+			Adults:     r.Adults,
+			Children:   r.Children,
+			Infants:    r.Infants,
+			Cabin:      r.Cabin,
+			NonStop:    r.NonStop,
+			MaxResults: r.MaxResults,
+			Currency:   r.Currency,
+		}.normalize(),
+	}
+	if err := q.Validate(); err != nil {
+		return MultiCityQuery{}, err
+	}
+
+	for i, l := range r.Legs {
+		date, err := time.Parse("2006-01-02", l.Date)
+		if err != nil {
+			return MultiCityQuery{}, fmt.Errorf("leg %d: invalid date %q", i+1, l.Date)
+		}
+		if i > 0 && date.Before(q.Legs[i-1].Date) {
+			return MultiCityQuery{}, fmt.Errorf("leg %d departs before leg %d", i+1, i)
+		}
+		leg := Leg{
+			Origin:      strings.ToUpper(l.Origin),
+			Destination: strings.ToUpper(l.Destination),
+			Date:        date,
+		}
+		if leg.Origin == leg.Destination {
+			return MultiCityQuery{}, fmt.Errorf("leg %d: origin and destination are the same", i+1)
+		}
+		q.Legs = append(q.Legs, leg)
+	}
+
+	return q, nil
+}
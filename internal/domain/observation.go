@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Observation is a price seen for a route at a point in time
+type Observation struct {
+	Origin        string    `json:"origin"`
+	Destination   string    `json:"destination"`
+	DepartureDate time.Time `json:"departure_date"`
+	ReturnDate    time.Time `json:"return_date,omitzero"` // zero for one-way trips
+	Provider      string    `json:"provider"`
+	Airline       string    `json:"airline"`
+	Cabin         string    `json:"cabin"`      // one of the Cabin* constants
+	Passengers    int       `json:"passengers"` // travelers the price is for
+	Price         Money     `json:"price"`
+	ObservedAt    time.Time `json:"observed_at"`
+}
+
+// Defaults fills in the cabin and passengers of observations recorded before
+// they were kept, which all came from searches with the default travelers
+func (o Observation) Defaults() Observation {
+	if o.Cabin == "" {
+		o.Cabin = CabinEconomy
+	}
+	if o.Passengers == 0 {
+		o.Passengers = DefaultAdults
+	}
+	return o
+}
+
+// MonthlyPrice aggregates the prices observed during one month
+type MonthlyPrice struct {
+	Month   string // YYYY-MM
+	Min     Money
+	Median  Money
+	Average Money
+	Count   int
+}
+
+// MarshalJSON encodes the prices as numbers next to their currency, like the
+// earlier {"month","avgPrice","currency"} history entries
+func (m MonthlyPrice) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Month    string      `json:"month"`
+		Min      json.Number `json:"minPrice"`
+		Median   json.Number `json:"medianPrice"`
+		Average  json.Number `json:"avgPrice"`
+		Count    int         `json:"count"`
+		Currency string      `json:"currency"`
+	}{m.Month, json.Number(m.Min.String()), json.Number(m.Median.String()), json.Number(m.Average.String()), m.Count, m.Average.Currency})
+}
@@ -0,0 +1,27 @@
+package domain
+
+// Price move directions reported in PriceMove.Change
+const (
+	PriceUp   = "up"
+	PriceDown = "down"
+	OfferNew  = "new"
+	OfferGone = "gone"
+)
+
+// PriceMove describes how the price of one offer changed between two polls
+type PriceMove struct {
+	Offer    string `json:"offer"` // stable key of the itinerary
+	Provider string `json:"provider"`
+	Airline  string `json:"airline"`
+	Change   string `json:"change"`
+	From     *Money `json:"from,omitempty"` // nil for new offers
+	To       *Money `json:"to,omitempty"`   // nil for offers that are gone
+}
+
+// OffersDiff lists the price moves between two versions of a search result
+type OffersDiff struct {
+	From     string      `json:"from"` // version of the previous result
+	To       string      `json:"to"`
+	Cheapest *PriceMove  `json:"cheapest,omitempty"` // set when the cheapest price moved
+	Moves    []PriceMove `json:"moves"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// Provider outcomes reported in ProviderStatus.Status
+const (
+	ProviderOK      = "ok"
+	ProviderError   = "error"
+	ProviderTimeout = "timeout"
+	ProviderSkipped = "skipped" // the provider can't serve this kind of search
+)
+
+// ProviderStatus reports how a single provider fared during a search
+type ProviderStatus struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Quotes    int    `json:"quotes"`
+	Error     string `json:"error,omitempty"` // safe to show to clients, never the raw provider response
+}
+
+// ProviderResult is a single provider's answer, streamed before the aggregated response
+type ProviderResult struct {
+	ProviderStatus
+	Offers []Quote `json:"offers"`
+}
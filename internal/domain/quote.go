@@ -1,15 +1,153 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Quote struct {
-	Provider    string        `json:"provider"`
-	Airline     string        `json:"airline"`
-	Price       float64       `json:"price"`
-	Currency    string        `json:"currency"`
-	Duration    time.Duration `json:"duration"`
-	DepartureAt time.Time     `json:"departure_at"`
-	ArrivalAt   time.Time     `json:"arrival_at"`
+	Provider       string          `json:"provider"`
+	Airline        string          `json:"airline"`
+	Price          Money           `json:"-"` // encoded as "price" and "currency", see MarshalJSON
+	OriginalPrice  *Money          `json:"-"` // price as quoted by the provider, set when converted
+	Duration       time.Duration   `json:"duration"`
+	DepartureAt    time.Time       `json:"departure_at"` // local time at the origin airport
+	ArrivalAt      time.Time       `json:"arrival_at"`   // local time at the destination airport
+	DepartureAtUTC time.Time       `json:"departure_at_utc"`
+	ArrivalAtUTC   time.Time       `json:"arrival_at_utc"`
+	Origin         string          `json:"origin"`
+	Destination    string          `json:"destination"`
+	Outbound       *Itinerary      `json:"outbound,omitempty"`
+	Inbound        *Itinerary      `json:"inbound,omitempty"`     // nil for one-way trips or when the provider only prices the outbound
+	Itineraries    []Itinerary     `json:"itineraries,omitempty"` // one per leg of a multi-city trip
+	Providers      []ProviderPrice `json:"providers,omitempty"`   // every provider selling this itinerary, when several do
+}
+
+// ProviderPrice is one provider's price for an itinerary
+type ProviderPrice struct {
+	Provider      string `json:"provider"`
+	Price         Money  `json:"price"`
+	OriginalPrice *Money `json:"original_price,omitempty"`
+}
+
+// Itinerary describes a single flown leg of a quote
+type Itinerary struct {
 	Origin      string        `json:"origin"`
 	Destination string        `json:"destination"`
+	DepartureAt time.Time     `json:"departure_at"`
+	ArrivalAt   time.Time     `json:"arrival_at"`
+	Duration    time.Duration `json:"duration"`
+	Stops       int           `json:"stops"` // connections plus technical stops
+	Segments    []Segment     `json:"segments,omitempty"`
+}
+
+// Segment is a single flight between two airports
+type Segment struct {
+	Carrier           string        `json:"carrier"`
+	FlightNumber      string        `json:"flight_number"`
+	OperatingCarrier  string        `json:"operating_carrier,omitempty"`
+	Aircraft          string        `json:"aircraft,omitempty"`
+	Origin            string        `json:"origin"`
+	DepartureTerminal string        `json:"departure_terminal,omitempty"`
+	DepartureAt       time.Time     `json:"departure_at"`
+	Destination       string        `json:"destination"`
+	ArrivalTerminal   string        `json:"arrival_terminal,omitempty"`
+	ArrivalAt         time.Time     `json:"arrival_at"`
+	Duration          time.Duration `json:"duration"`
+	Stops             int           `json:"stops"` // technical stops without a change of aircraft
+}
+
+// NewItinerary builds an itinerary from its segments, which must be in flight order
+func NewItinerary(segments []Segment) Itinerary {
+	first := segments[0]
+	last := segments[len(segments)-1]
+
+	stops := len(segments) - 1
+	for _, s := range segments {
+		stops += s.Stops
+	}
+
+	return Itinerary{
+		Origin:      first.Origin,
+		Destination: last.Destination,
+		DepartureAt: first.DepartureAt,
+		ArrivalAt:   last.ArrivalAt,
+		Duration:    last.ArrivalAt.Sub(first.DepartureAt), // exact as long as times carry their airport's zone
+		Stops:       stops,
+		Segments:    segments,
+	}
+}
+
+// Stops returns the highest number of stops on any leg of the quote, or -1
+// when the provider gave no segment details
+func (q Quote) Stops() int {
+	stops := -1
+	for _, it := range q.Legs() {
+		stops = max(stops, it.Stops)
+	}
+	return stops
+}
+
+// Legs returns every itinerary of the quote in flight order
+func (q Quote) Legs() []Itinerary {
+	if len(q.Itineraries) > 0 {
+		return q.Itineraries
+	}
+	legs := make([]Itinerary, 0, 2)
+	if q.Outbound != nil {
+		legs = append(legs, *q.Outbound)
+	}
+	if q.Inbound != nil {
+		legs = append(legs, *q.Inbound)
+	}
+	return legs
+}
+
+// quoteAlias drops Quote's methods so it can be embedded in its wire format
+type quoteAlias Quote
+
+// quoteJSON keeps prices as plain numbers next to their currency, the way
+// clients have always read them
+type quoteJSON struct {
+	quoteAlias
+	Price            json.Number `json:"price"`
+	Currency         string      `json:"currency"`
+	OriginalPrice    json.Number `json:"original_price,omitempty"`
+	OriginalCurrency string      `json:"original_currency,omitempty"`
+}
+
+func (q Quote) MarshalJSON() ([]byte, error) {
+	out := quoteJSON{
+		quoteAlias: quoteAlias(q),
+		Price:      json.Number(q.Price.String()),
+		Currency:   q.Price.Currency,
+	}
+	if q.OriginalPrice != nil {
+		out.OriginalPrice = json.Number(q.OriginalPrice.String())
+		out.OriginalCurrency = q.OriginalPrice.Currency
+	}
+	return json.Marshal(out)
+}
+
+func (q *Quote) UnmarshalJSON(b []byte) error {
+	var in quoteJSON
+	if err := json.Unmarshal(b, &in); err != nil {
+		return err
+	}
+	*q = Quote(in.quoteAlias)
+
+	price, err := ParseMoney(in.Price.String(), in.Currency)
+	if err != nil {
+		return err
+	}
+	q.Price = price
+
+	if in.OriginalPrice != "" {
+		orig, err := ParseMoney(in.OriginalPrice.String(), in.OriginalCurrency)
+		if err != nil {
+			return err
+		}
+		q.OriginalPrice = &orig
+	}
+	return nil
 }
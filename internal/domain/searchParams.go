@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// SearchParams is the JSON form of SearchRequest, for searches sent in a
+// message body rather than a query string
+type SearchParams struct {
+	Origin      string  `json:"origin" binding:"required,len=3"`
+	Destination string  `json:"destination" binding:"required,len=3"`
+	StartDate   string  `json:"startDate" binding:"required,datetime=2006-01-02"`
+	EndDate     string  `json:"endDate,omitempty" binding:"omitempty,datetime=2006-01-02"` // empty for one-way
+	Adults      int     `json:"adults,omitempty" binding:"omitempty,min=1,max=9"`
+	Children    int     `json:"children,omitempty" binding:"omitempty,min=0,max=9"`
+	Infants     int     `json:"infants,omitempty" binding:"omitempty,min=0,max=9"`
+	Cabin       string  `json:"cabin,omitempty" binding:"omitempty,oneof=ECONOMY PREMIUM_ECONOMY BUSINESS FIRST economy premium_economy business first"`
+	NonStop     bool    `json:"nonStop,omitempty"`
+	MaxResults  int     `json:"max,omitempty" binding:"omitempty,min=1,max=250"`
+	Currency    string  `json:"currency,omitempty" binding:"omitempty,len=3"`
+	NearbyKm    float64 `json:"nearbyKm,omitempty" binding:"omitempty,min=0,max=500"`
+}
+
+// Request converts the params into a SearchRequest, parsing the dates
+func (p SearchParams) Request() (SearchRequest, error) {
+	req := SearchRequest{
+		Origin:      p.Origin,
+		Destination: p.Destination,
+		Adults:      p.Adults,
+		Children:    p.Children,
+		Infants:     p.Infants,
+		Cabin:       p.Cabin,
+		NonStop:     p.NonStop,
+		MaxResults:  p.MaxResults,
+		Currency:    p.Currency,
+		NearbyKm:    p.NearbyKm,
+	}
+
+	var err error
+	if req.StartDate, err = time.Parse("2006-01-02", p.StartDate); err != nil {
+		return req, fmt.Errorf("invalid startDate %q", p.StartDate)
+	}
+	if p.EndDate != "" {
+		if req.EndDate, err = time.Parse("2006-01-02", p.EndDate); err != nil {
+			return req, fmt.Errorf("invalid endDate %q", p.EndDate)
+		}
+		if req.EndDate.Before(req.StartDate) {
+			return req, fmt.Errorf("endDate %s is before startDate %s", p.EndDate, p.StartDate)
+		}
+	}
+	return req, nil
+}
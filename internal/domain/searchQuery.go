@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cabin classes understood by every provider
+const (
+	CabinEconomy        = "ECONOMY"
+	CabinPremiumEconomy = "PREMIUM_ECONOMY"
+	CabinBusiness       = "BUSINESS"
+	CabinFirst          = "FIRST"
+)
+
+const (
+	DefaultAdults     = 1
+	DefaultMaxResults = 10
+	DefaultCurrency   = "USD"
+)
+
+// MaxSeatedTravelers is the most adults and children a single booking can seat
+const MaxSeatedTravelers = 9
+
+// ErrInvalidTravelers is returned for traveler counts no booking can hold
+var ErrInvalidTravelers = errors.New("invalid travelers")
+
+// SearchOptions are the traveler and filtering preferences shared by every kind of search
+type SearchOptions struct {
+	Adults     int
+	Children   int
+	Infants    int
+	Cabin      string // one of the Cabin* constants, empty means any
+	NonStop    bool
+	MaxResults int
+	Currency   string // ISO 4217
+}
+
+// SearchQuery is the provider-agnostic description of a flight search
+type SearchQuery struct {
+	Origin        string
+	Destination   string
+	DepartureDate time.Time
+	ReturnDate    time.Time // zero for one-way trips
+	SearchOptions
+}
+
+// Passengers returns the total number of travelers
+func (o SearchOptions) Passengers() int {
+	return o.Adults + o.Children + o.Infants
+}
+
+// Validate checks that every infant has an adult to sit on and that the
+// seated travelers fit in one booking
+func (o SearchOptions) Validate() error {
+	if o.Infants > o.Adults {
+		return fmt.Errorf("%w: %d infants but only %d adults, each infant travels on an adult's lap", ErrInvalidTravelers, o.Infants, o.Adults)
+	}
+	if seated := o.Adults + o.Children; seated > MaxSeatedTravelers {
+		return fmt.Errorf("%w: %d adults and children, at most %d", ErrInvalidTravelers, seated, MaxSeatedTravelers)
+	}
+	return nil
+}
+
+// key identifies the options, e.g. as part of a cache key
+func (o SearchOptions) key() string {
+	return fmt.Sprintf("a%d|c%d|i%d|%s|ns=%t|max%d|%s",
+		o.Adults, o.Children, o.Infants,
+		o.Cabin, o.NonStop, o.MaxResults, o.Currency)
+}
+
+// normalize upper-cases codes and applies defaults
+func (o SearchOptions) normalize() SearchOptions {
+	o.Cabin = strings.ToUpper(o.Cabin)
+	o.Currency = strings.ToUpper(o.Currency)
+	if o.Adults == 0 {
+		o.Adults = DefaultAdults
+	}
+	if o.MaxResults == 0 {
+		o.MaxResults = DefaultMaxResults
+	}
+	if o.Currency == "" {
+		o.Currency = DefaultCurrency
+	}
+	return o
+}
+
+// OneWay reports whether the query has no return leg
+func (q SearchQuery) OneWay() bool {
+	return q.ReturnDate.IsZero()
+}
+
+// Key uniquely identifies the query, e.g. for caching
+func (q SearchQuery) Key() string {
+	ret := "oneway"
+	if !q.OneWay() {
+		ret = q.ReturnDate.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s",
+		q.Origin,
+		q.Destination,
+		q.DepartureDate.Format("2006-01-02"),
+		ret,
+		q.SearchOptions.key())
+}
+
+// Query converts the HTTP request into a SearchQuery, applying defaults
+func (r SearchRequest) Query() SearchQuery {
+	return SearchQuery{
+		Origin:        strings.ToUpper(r.Origin),
+		Destination:   strings.ToUpper(r.Destination),
+		DepartureDate: r.StartDate,
+		ReturnDate:    r.EndDate,
+		SearchOptions: SearchOptions{
+			Adults:     r.Adults,
+			Children:   r.Children,
+			Infants:    r.Infants,
+			Cabin:      r.Cabin,
+			NonStop:    r.NonStop,
+			MaxResults: r.MaxResults,
+			Currency:   r.Currency,
+		}.normalize(),
+	}
+}
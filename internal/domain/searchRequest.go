@@ -6,5 +6,13 @@ type SearchRequest struct {
 	Origin      string    `form:"origin" binding:"required,len=3"`
 	Destination string    `form:"destination" binding:"required,len=3"`
 	StartDate   time.Time `form:"starDate" time_format:"2006-01-02" binding:"required"`
-	EndDate     time.Time `form:"endDate" time_format:"2006-01-02" binding:"required"`
+	EndDate     time.Time `form:"endDate" time_format:"2006-01-02" binding:"omitempty,gtefield=StartDate"` // empty for one-way
+	Adults      int       `form:"adults" binding:"omitempty,min=1,max=9"`
+	Children    int       `form:"children" binding:"omitempty,min=0,max=9"`
+	Infants     int       `form:"infants" binding:"omitempty,min=0,max=9"`
+	Cabin       string    `form:"cabin" binding:"omitempty,oneof=ECONOMY PREMIUM_ECONOMY BUSINESS FIRST economy premium_economy business first"`
+	NonStop     bool      `form:"nonStop"`
+	MaxResults  int       `form:"max" binding:"omitempty,min=1,max=250"`
+	Currency    string    `form:"currency" binding:"omitempty,len=3"`
+	NearbyKm    float64   `form:"nearbyKm" binding:"omitempty,min=0,max=500"` // also search airports this close to the origin
 }
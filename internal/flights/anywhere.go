@@ -0,0 +1,151 @@
+package flights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// ErrAnywhereUnsupported is returned when no registered provider can list destinations
+var ErrAnywhereUnsupported = errors.New("no provider supports destination search")
+
+// Anywhere lists the destinations reachable from the origin within the budget,
+// cheapest first. Every provider able to list destinations is asked
+// concurrently; prices are converted to the budget's currency and the
+// cheapest one per destination is kept.
+func (s *Service) Anywhere(ctx context.Context, q domain.AnywhereQuery) (domain.AnywhereResponse, error) {
+	var err error
+	if q.Origin, err = normalizeLocation("origin", q.Origin); err != nil {
+		return domain.AnywhereResponse{}, err
+	}
+	if err := checkWindow(q.From, q.To, q.MinDays, q.MaxDays); err != nil {
+		return domain.AnywhereResponse{}, err
+	}
+
+	var capable []providers.DestinationProvider
+	for _, p := range s.snapshot() {
+		if dp, ok := p.(providers.DestinationProvider); ok {
+			capable = append(capable, dp)
+		}
+	}
+	if len(capable) == 0 {
+		return domain.AnywhereResponse{}, ErrAnywhereUnsupported
+	}
+
+	found, err := s.destinations(ctx, capable, q)
+	if err != nil {
+		return domain.AnywhereResponse{}, err
+	}
+
+	best := make(map[string]int)
+	dests := make([]domain.DestinationPrice, 0, len(found))
+	for _, d := range found {
+		if d.Price, err = s.convert(ctx, d.Price, q.MaxPrice.Currency); err != nil {
+			log.Printf("✗ Dropping %s price for %s: %v", d.Provider, d.Destination, err)
+			continue
+		}
+		if q.MaxPrice.Less(d.Price) {
+			continue
+		}
+		if i, seen := best[d.Destination]; seen {
+			if d.Price.Less(dests[i].Price) {
+				dests[i] = d
+			}
+			continue
+		}
+		best[d.Destination] = len(dests)
+		dests = append(dests, d)
+	}
+
+	slices.SortFunc(dests, func(a, b domain.DestinationPrice) int {
+		switch {
+		case a.Price.Less(b.Price):
+			return -1
+		case b.Price.Less(a.Price):
+			return 1
+		}
+		return strings.Compare(a.Destination, b.Destination)
+	})
+	if q.Limit > 0 && len(dests) > q.Limit {
+		dests = dests[:q.Limit]
+	}
+	for i := range dests {
+		d := &dests[i]
+		d.Name, d.City, d.Country = place(d.Destination)
+	}
+
+	return domain.AnywhereResponse{
+		Origin:       q.Origin,
+		From:         q.From.Format("2006-01-02"),
+		To:           q.To.Format("2006-01-02"),
+		MaxPrice:     q.MaxPrice,
+		Destinations: dests,
+	}, nil
+}
+
+// destinations asks the providers concurrently and fails only when all of them do
+func (s *Service) destinations(ctx context.Context, provs []providers.DestinationProvider, q domain.AnywhereQuery) ([]domain.DestinationPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		out  []domain.DestinationPrice
+		errs []string
+	)
+	for _, p := range provs {
+		wg.Go(func() {
+			dests, err := p.Destinations(ctx, q)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("✗ Destination search failed on %s: %v", p.Name(), err)
+				// raw errors may carry the provider's response body, so only
+				// the classified message reaches the client
+				_, msg := classify(err)
+				errs = append(errs, p.Name()+": "+msg)
+				return
+			}
+			log.Printf("✓ Provider %s returned %d destinations", p.Name(), len(dests))
+			out = append(out, dests...)
+		})
+	}
+	wg.Wait()
+
+	if len(errs) == len(provs) {
+		slices.Sort(errs)
+		return nil, fmt.Errorf("destination search failed: %s", strings.Join(errs, "; "))
+	}
+	return out, nil
+}
+
+// place returns the name, city and country of an airport or city code
+func place(code string) (name, city, country string) {
+	if a, ok := airports.Lookup(code); ok {
+		return a.Name, a.City, a.Country
+	}
+	as, ok := airports.CityAirports(code)
+	if !ok || len(as) == 0 {
+		return "", "", ""
+	}
+	// secondary airports may sit in another town, e.g. Beauvais for PAR: take
+	// the town most of the city's airports are in
+	count := make(map[string]int)
+	top := as[0]
+	for _, a := range as {
+		count[a.City]++
+		if count[a.City] > count[top.City] {
+			top = a
+		}
+	}
+	return top.City, top.City, top.Country
+}
@@ -1,68 +1,65 @@
 package flights
 
 import (
-	"sync"
+	"encoding/json"
 	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/cache"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// Default bounds of the response cache
+const (
+	DefaultCacheEntries = 10_000
+	DefaultCacheBytes   = 64 << 20
 )
 
+// How long responses are cached. Partial responses, where some provider
+// failed or timed out, expire sooner so a recovered provider is asked again.
+const (
+	cacheTTL        = 30 * time.Second
+	partialCacheTTL = 5 * time.Second
+)
+
+// Cache stores aggregated responses by search key
 type Cache interface {
-	Get(key string) (any, bool)
-	Set(key string, val any, ttl time.Duration)
+	Get(key string) (domain.AggregatedResponse, bool)
+	Set(key string, resp domain.AggregatedResponse, ttl time.Duration)
 	Clear()
 }
 
-type InMemoryTTL struct {
-	mu sync.RWMutex
-	m  map[string]entry
-}
+// ResponseCache is the in-memory LRU cache of aggregated responses
+type ResponseCache = cache.Cache[string, domain.AggregatedResponse]
 
-type entry struct {
-	v   any
-	exp time.Time
+// NewInMemoryTTL returns a response cache with the default bounds
+func NewInMemoryTTL() *ResponseCache {
+	return NewResponseCache(DefaultCacheEntries, DefaultCacheBytes)
 }
 
-func NewInMemoryTTL() *InMemoryTTL {
-	return &InMemoryTTL{m: make(map[string]entry)}
+// NewResponseCache returns a response cache holding at most maxEntries
+// responses and about maxBytes of JSON; zero means unbounded
+func NewResponseCache(maxEntries int, maxBytes int64) *ResponseCache {
+	return cache.New[string](cache.Options[domain.AggregatedResponse]{
+		MaxEntries: maxEntries,
+		MaxBytes:   maxBytes,
+		Size:       responseSize,
+	})
 }
 
-func (c *InMemoryTTL) Get(k string) (any, bool) {
-	c.mu.RLock()
-	e, ok := c.m[k]
-	c.mu.RUnlock()
-	if !ok || time.Now().After(e.exp) {
-		return nil, false
+// responseSize estimates the memory a response takes by its JSON encoding
+func responseSize(resp domain.AggregatedResponse) int64 {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return 0
 	}
-	return e.v, true
+	return int64(len(b))
 }
 
-func (c *InMemoryTTL) Set(k string, v any, ttl time.Duration) {
-	c.mu.Lock()
-	c.m[k] = entry{v: v, exp: time.Now().Add(ttl)}
-	c.mu.Unlock()
-}
-
-func (c *InMemoryTTL) Clear() {
-	c.mu.Lock()
-	c.m = make(map[string]entry)
-	c.mu.Unlock()
-}
-
-// StartCleanup starts a goroutine that periodically removes expired entries
-func (c *InMemoryTTL) StartCleanup(interval time.Duration) {
-	go func() {
-		ticker := time.NewTicker(interval)
-		defer ticker.Stop()
-
-		for range ticker.C {
-			now := time.Now()
-
-			c.mu.Lock()
-			for k, e := range c.m {
-				if now.After(e.exp) {
-					delete(c.m, k)
-				}
-			}
-			c.mu.Unlock()
-		}
-	}()
+// CacheStats returns the counters of the response cache, when it keeps any
+func (s *Service) CacheStats() (cache.Stats, bool) {
+	st, ok := s.cache.(interface{ Stats() cache.Stats })
+	if !ok {
+		return cache.Stats{}, false
+	}
+	return st.Stats(), true
 }
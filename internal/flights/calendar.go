@@ -0,0 +1,150 @@
+package flights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+// calendarConcurrency bounds the date combinations searched at once
+const calendarConcurrency = 4
+
+// maxCalendarSearches bounds the provider searches of a calendar: every date
+// combination times every airport pair of metro areas and nearby airports
+const maxCalendarSearches = 49
+
+// ErrSearchTooLarge is returned when a search would fan out to too many provider calls
+var ErrSearchTooLarge = errors.New("search too large")
+
+// Calendar searches every combination of departure and return dates within
+// the flex windows and returns the cheapest price of each. Dates before today
+// are left out. Each combination goes through Search, so cached results are
+// reused.
+func (s *Service) Calendar(ctx context.Context, req domain.CalendarRequest) (domain.PriceCalendar, error) {
+	sp, err := plan(req.SearchRequest)
+	if err != nil {
+		return domain.PriceCalendar{}, err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	departures := notBefore(flexDates(req.StartDate, req.DepartFlex), today)
+	var returns []time.Time
+	if !req.EndDate.IsZero() {
+		returns = notBefore(flexDates(req.EndDate, req.ReturnFlex), today)
+		if len(returns) == 0 {
+			return domain.PriceCalendar{}, fmt.Errorf("%w: every return date is in the past", ErrInvalidPeriod)
+		}
+	}
+	if len(departures) == 0 {
+		return domain.PriceCalendar{}, fmt.Errorf("%w: every departure date is in the past", ErrInvalidPeriod)
+	}
+	combos := len(departures) * max(len(returns), 1)
+	if n := combos * len(sp.pairs); n > maxCalendarSearches {
+		return domain.PriceCalendar{}, fmt.Errorf("%w: %d searches (%d date combinations × %d airport pairs), at most %d; narrow the flex windows or the airports",
+			ErrSearchTooLarge, n, combos, len(sp.pairs), maxCalendarSearches)
+	}
+
+	cal := domain.PriceCalendar{
+		Origin:         strings.ToUpper(req.Origin),
+		Destination:    strings.ToUpper(req.Destination),
+		DepartureDates: formatDates(departures),
+		ReturnDates:    formatDates(returns),
+		Cells:          make([][]domain.CalendarCell, len(departures)),
+		SkippedPairs:   sp.skipped,
+	}
+
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(calendarConcurrency)
+	for i, dep := range departures {
+		cal.Cells[i] = make([]domain.CalendarCell, max(len(returns), 1))
+		for j := range cal.Cells[i] {
+			cell := &cal.Cells[i][j]
+			cell.DepartureDate = dep.Format("2006-01-02")
+
+			r := req.SearchRequest
+			r.StartDate = dep
+			if len(returns) > 0 {
+				r.EndDate = returns[j]
+				cell.ReturnDate = returns[j].Format("2006-01-02")
+				if r.EndDate.Before(r.StartDate) {
+					cell.Error = "return before departure"
+					continue
+				}
+			}
+
+			eg.Go(func() error {
+				resp, err := s.Search(gctx, r)
+				fillCell(cell, resp, err)
+				return nil
+			})
+		}
+	}
+	_ = eg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return domain.PriceCalendar{}, err
+	}
+
+	var cheapest *domain.CalendarCell
+	for i := range cal.Cells {
+		for j := range cal.Cells[i] {
+			c := &cal.Cells[i][j]
+			if c.Price != nil && (cheapest == nil || c.Price.Less(*cheapest.Price)) {
+				cheapest = c
+			}
+		}
+	}
+	if cheapest != nil {
+		cheapest.Cheapest = true
+		top := *cheapest
+		cal.Cheapest = &top
+	}
+	return cal, nil
+}
+
+// fillCell records the cheapest offer of a search, or why there is none
+func fillCell(cell *domain.CalendarCell, resp domain.AggregatedResponse, err error) {
+	if err != nil {
+		cell.Error = err.Error()
+		return
+	}
+	if resp.Cheapest == nil {
+		cell.Error = "no offers"
+		return
+	}
+	price := resp.Cheapest.Price
+	cell.Price = &price
+	cell.Provider = resp.Cheapest.Provider
+	cell.Airline = resp.Cheapest.Airline
+}
+
+// notBefore drops the dates before day
+func notBefore(dates []time.Time, day time.Time) []time.Time {
+	return slices.DeleteFunc(dates, func(d time.Time) bool { return d.Before(day) })
+}
+
+// flexDates returns the days from date-flex to date+flex
+func flexDates(date time.Time, flex int) []time.Time {
+	out := make([]time.Time, 0, 2*flex+1)
+	for d := -flex; d <= flex; d++ {
+		out = append(out, date.AddDate(0, 0, d))
+	}
+	return out
+}
+
+func formatDates(ds []time.Time) []string {
+	if len(ds) == 0 {
+		return nil
+	}
+	out := make([]string, len(ds))
+	for i, d := range ds {
+		out[i] = d.Format("2006-01-02")
+	}
+	return out
+}
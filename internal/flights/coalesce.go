@@ -0,0 +1,79 @@
+package flights
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// coalescer collapses concurrent identical searches into one computation
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// call is a computation in flight and the callers waiting for it
+type call struct {
+	done    chan struct{}
+	resp    domain.AggregatedResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs compute once for all the concurrent callers of the same key and
+// hands its result to each of them. compute runs on a context detached from
+// the callers' cancellation, canceled only once every caller has given up.
+func (g *coalescer) do(ctx context.Context, key string, compute func(context.Context) (domain.AggregatedResponse, error)) (domain.AggregatedResponse, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		cctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go func() {
+			c.resp, c.err = compute(cctx)
+			g.forget(key, c)
+			cancel()
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	if ok {
+		log.Printf("✓ Joined in-flight search %s", key)
+	}
+
+	select {
+	case <-c.done:
+		return c.resp, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// nobody is waiting anymore: stop the providers and let the next
+			// caller start afresh
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+			c.cancel()
+		}
+		g.mu.Unlock()
+		return domain.AggregatedResponse{}, ctx.Err()
+	}
+}
+
+// forget stops new callers from joining c
+func (g *coalescer) forget(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
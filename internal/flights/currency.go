@@ -0,0 +1,70 @@
+package flights
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+)
+
+// SetRateSource configures the FX rates used to convert quotes into the
+// requested currency before ranking
+func (s *Service) SetRateSource(r fx.RateSource) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = r
+}
+
+// errNoRates is returned when a price must be converted but no rate source is configured
+var errNoRates = errors.New("no exchange rates configured")
+
+// convert returns m in the given currency
+func (s *Service) convert(ctx context.Context, m domain.Money, currency string) (domain.Money, error) {
+	if strings.EqualFold(m.Currency, currency) {
+		return m, nil
+	}
+	s.mu.RLock()
+	rates := s.rates
+	s.mu.RUnlock()
+	if rates == nil {
+		return m, errNoRates
+	}
+	return fx.Convert(ctx, rates, m, currency)
+}
+
+// inCurrency converts the quotes into the given currency, keeping the provider's
+// original amount. Quotes that can't be converted, including every quote in
+// another currency when no rate source is configured, are dropped, since their
+// price can't be compared with the others.
+func (s *Service) inCurrency(ctx context.Context, qs []domain.Quote, currency string) ([]domain.Quote, error) {
+	if currency == "" {
+		return qs, nil
+	}
+
+	out := make([]domain.Quote, 0, len(qs))
+	var lastErr error
+	for _, q := range qs {
+		if strings.EqualFold(q.Price.Currency, currency) {
+			out = append(out, q)
+			continue
+		}
+		converted, err := s.convert(ctx, q.Price, currency)
+		if err != nil {
+			log.Printf("✗ Dropping %s quote: cannot convert %s to %s: %v", q.Provider, q.Price.Currency, currency, err)
+			lastErr = err
+			continue
+		}
+		original := q.Price
+		q.OriginalPrice = &original
+		q.Price = converted
+		out = append(out, q)
+	}
+
+	if len(out) == 0 && lastErr != nil {
+		return nil, errors.Join(errors.New("no quote could be converted"), lastErr)
+	}
+	return out, nil
+}
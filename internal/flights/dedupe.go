@@ -0,0 +1,127 @@
+package flights
+
+import (
+	"strings"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// dedupe collapses quotes for the same physical itinerary, possibly from
+// different providers, into a single offer listing every provider's price.
+// Quotes merge only when every leg matches. The cheapest price becomes the
+// headline. Quotes without segment details can't be fingerprinted and are
+// kept as they are.
+func dedupe(all []domain.Quote) []domain.Quote {
+	type group struct {
+		quotes []domain.Quote
+	}
+
+	groups := make(map[string]*group)
+	order := make([]*group, 0, len(all))
+
+	for _, q := range all {
+		out, in, ok := fingerprints(q)
+		if !ok {
+			order = append(order, &group{quotes: []domain.Quote{q}})
+			continue
+		}
+
+		// a round trip whose provider only described the outbound has an empty
+		// inbound, so it never merges with one whose return flight may differ.
+		// Google Flights round trips are all like this, so they only merge
+		// with each other, never with Amadeus'. Every quote of a search shares
+		// its return date, so the outbound alone identifies them here.
+		key := out + "|" + in
+		g, exists := groups[key]
+		if !exists {
+			g = &group{}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.quotes = append(g.quotes, q)
+	}
+
+	merged := make([]domain.Quote, 0, len(order))
+	for _, g := range order {
+		merged = append(merged, mergeGroup(g.quotes))
+	}
+	return merged
+}
+
+// mergeGroup picks the most detailed quote as the base and the cheapest price
+// as the headline, listing the best price of each provider
+func mergeGroup(qs []domain.Quote) domain.Quote {
+	if len(qs) == 1 {
+		return qs[0]
+	}
+
+	base, cheapest := qs[0], qs[0]
+	best := make(map[string]domain.ProviderPrice, len(qs))
+	providerOrder := make([]string, 0, len(qs))
+	for _, q := range qs {
+		if len(q.Legs()) > len(base.Legs()) {
+			base = q
+		}
+		if q.Price.Less(cheapest.Price) {
+			cheapest = q
+		}
+		p, seen := best[q.Provider]
+		if !seen {
+			providerOrder = append(providerOrder, q.Provider)
+		}
+		if !seen || q.Price.Less(p.Price) {
+			best[q.Provider] = domain.ProviderPrice{Provider: q.Provider, Price: q.Price, OriginalPrice: q.OriginalPrice}
+		}
+	}
+
+	out := base
+	out.Provider = cheapest.Provider
+	out.Price = cheapest.Price
+	out.OriginalPrice = cheapest.OriginalPrice
+	out.Providers = make([]domain.ProviderPrice, 0, len(best))
+	for _, name := range providerOrder {
+		out.Providers = append(out.Providers, best[name])
+	}
+	return out
+}
+
+// fingerprints identifies the outbound (or every multi-city leg) and the inbound
+// of a quote by flight numbers and departure times
+func fingerprints(q domain.Quote) (outbound, inbound string, ok bool) {
+	if len(q.Itineraries) > 0 {
+		parts := make([]string, 0, len(q.Itineraries))
+		for _, it := range q.Itineraries {
+			fp, ok := legFingerprint(&it)
+			if !ok {
+				return "", "", false
+			}
+			parts = append(parts, fp)
+		}
+		return strings.Join(parts, "/"), "", true
+	}
+
+	outbound, ok = legFingerprint(q.Outbound)
+	if !ok {
+		return "", "", false
+	}
+	if q.Inbound != nil {
+		if inbound, ok = legFingerprint(q.Inbound); !ok {
+			return "", "", false
+		}
+	}
+	return outbound, inbound, true
+}
+
+func legFingerprint(it *domain.Itinerary) (string, bool) {
+	if it == nil || len(it.Segments) == 0 {
+		return "", false
+	}
+	parts := make([]string, 0, len(it.Segments))
+	for _, s := range it.Segments {
+		if s.FlightNumber == "" || s.DepartureAt.IsZero() {
+			return "", false
+		}
+		parts = append(parts, s.FlightNumber+"@"+s.DepartureAt.UTC().Format("200601021504"))
+	}
+	return strings.Join(parts, ","), true
+}
@@ -0,0 +1,90 @@
+package flights
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// OfferKey identifies an offer across polls: by its flights when the provider
+// described them, otherwise by provider, airline and times
+func OfferKey(q domain.Quote) string {
+	if out, in, ok := fingerprints(q); ok {
+		return out + "|" + in
+	}
+	return fmt.Sprintf("%s|%s|%s-%s|%s|%s", q.Provider, q.Airline, q.Origin, q.Destination,
+		q.DepartureAtUTC.Format("200601021504"), q.ArrivalAtUTC.Format("200601021504"))
+}
+
+// Version fingerprints the offers and their prices, so two results with the
+// same version show the same offers at the same prices
+func Version(offers []domain.Quote) string {
+	lines := make([]string, 0, len(offers))
+	for _, q := range offers {
+		lines = append(lines, OfferKey(q)+"="+q.Provider+":"+q.Price.String()+q.Price.Currency)
+	}
+	sort.Strings(lines)
+
+	h := fnv.New64a()
+	for _, l := range lines {
+		h.Write([]byte(l))
+		h.Write([]byte{'\n'})
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// Diff reports the price moves from prev to next
+func Diff(prev, next domain.AggregatedResponse) domain.OffersDiff {
+	d := domain.OffersDiff{From: Version(prev.Offers), To: Version(next.Offers), Moves: []domain.PriceMove{}}
+
+	before := make(map[string]domain.Quote, len(prev.Offers))
+	for _, q := range prev.Offers {
+		before[OfferKey(q)] = q
+	}
+
+	seen := make(map[string]bool, len(next.Offers))
+	for _, q := range next.Offers {
+		key := OfferKey(q)
+		seen[key] = true
+		old, existed := before[key]
+		switch {
+		case !existed:
+			d.Moves = append(d.Moves, move(key, q, domain.OfferNew, nil, &q.Price))
+		case old.Price != q.Price:
+			d.Moves = append(d.Moves, move(key, q, direction(old.Price, q.Price), &old.Price, &q.Price))
+		}
+	}
+	for _, q := range prev.Offers {
+		if key := OfferKey(q); !seen[key] {
+			d.Moves = append(d.Moves, move(key, q, domain.OfferGone, &q.Price, nil))
+		}
+	}
+
+	if prev.Cheapest != nil && next.Cheapest != nil && prev.Cheapest.Price != next.Cheapest.Price {
+		m := move(OfferKey(*next.Cheapest), *next.Cheapest, direction(prev.Cheapest.Price, next.Cheapest.Price), &prev.Cheapest.Price, &next.Cheapest.Price)
+		d.Cheapest = &m
+	}
+	return d
+}
+
+func move(key string, q domain.Quote, change string, from, to *domain.Money) domain.PriceMove {
+	m := domain.PriceMove{Offer: key, Provider: q.Provider, Airline: q.Airline, Change: change}
+	if from != nil {
+		f := *from
+		m.From = &f
+	}
+	if to != nil {
+		t := *to
+		m.To = &t
+	}
+	return m
+}
+
+func direction(from, to domain.Money) string {
+	if to.Less(from) {
+		return domain.PriceDown
+	}
+	return domain.PriceUp
+}
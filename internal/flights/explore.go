@@ -0,0 +1,264 @@
+package flights
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxExploreDays bounds the departure window of an explorer search
+const maxExploreDays = 366
+
+// exploreSamples is how many departure dates are searched when no provider
+// can find the cheapest dates itself
+const exploreSamples = 12
+
+// Explore ranks the departure/return date pairs of a route by price. Providers
+// with cheapest-date search are asked first; when none of them answers, a
+// sample of departure dates goes through Search and the gaps are filled with
+// estimates from the stored price observations.
+func (s *Service) Explore(ctx context.Context, req domain.ExploreRequest) (domain.ExploreResponse, error) {
+	q := req.Query()
+	var err error
+	if q.Origin, err = normalizeLocation("origin", q.Origin); err != nil {
+		return domain.ExploreResponse{}, err
+	}
+	if q.Destination, err = normalizeLocation("destination", q.Destination); err != nil {
+		return domain.ExploreResponse{}, err
+	}
+	if err := checkWindow(q.From, q.To, q.MinDays, q.MaxDays); err != nil {
+		return domain.ExploreResponse{}, err
+	}
+
+	prices := s.cheapestDates(ctx, q)
+	if len(prices) == 0 {
+		prices = append(s.sampleDates(ctx, q), s.observedDates(ctx, q)...)
+	}
+	if err := ctx.Err(); err != nil {
+		return domain.ExploreResponse{}, err
+	}
+
+	results := s.rankDates(ctx, prices, q.Currency)
+	limit := req.Limit
+	if limit == 0 {
+		limit = domain.DefaultExploreLimit
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return domain.ExploreResponse{
+		Origin:      q.Origin,
+		Destination: q.Destination,
+		From:        q.From.Format("2006-01-02"),
+		To:          q.To.Format("2006-01-02"),
+		MinDays:     q.MinDays,
+		MaxDays:     q.MaxDays,
+		Currency:    q.Currency,
+		Results:     results,
+	}, nil
+}
+
+// checkWindow validates a departure window and trip length range
+func checkWindow(from, to time.Time, minDays, maxDays int) error {
+	if to.Before(from) || to.Sub(from) > maxExploreDays*24*time.Hour {
+		return fmt.Errorf("%w: the departure window must span at most %d days", ErrInvalidPeriod, maxExploreDays)
+	}
+	if minDays > maxDays {
+		return fmt.Errorf("%w: minDays %d is above maxDays %d", ErrInvalidPeriod, minDays, maxDays)
+	}
+	return nil
+}
+
+// cheapestDates asks every provider with cheapest-date search concurrently
+func (s *Service) cheapestDates(ctx context.Context, q domain.CheapestDateQuery) []domain.DatePrice {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		out []domain.DatePrice
+	)
+	for _, p := range s.snapshot() {
+		cp, ok := p.(providers.CheapestDateProvider)
+		if !ok {
+			continue
+		}
+		wg.Go(func() {
+			prices, err := cp.CheapestDates(ctx, q)
+			if err != nil {
+				log.Printf("✗ Cheapest-date search failed on %s: %v", cp.Name(), err)
+				return
+			}
+			log.Printf("✓ Provider %s returned %d cheapest dates", cp.Name(), len(prices))
+			mu.Lock()
+			out = append(out, prices...)
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+	return out
+}
+
+// sampleDates searches evenly spaced departure dates of the window, staying
+// the middle of the trip length range
+func (s *Service) sampleDates(ctx context.Context, q domain.CheapestDateQuery) []domain.DatePrice {
+	days := int(q.To.Sub(q.From).Hours() / 24)
+	n := min(exploreSamples, days+1)
+	trip := (q.MinDays + q.MaxDays) / 2
+
+	found := make([]*domain.DatePrice, n)
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(calendarConcurrency)
+	for i := range n {
+		dep := q.From
+		if n > 1 {
+			dep = q.From.AddDate(0, 0, i*days/(n-1))
+		}
+		r := domain.SearchRequest{
+			Origin:      q.Origin,
+			Destination: q.Destination,
+			StartDate:   dep,
+			NonStop:     q.NonStop,
+			Currency:    q.Currency,
+		}
+		if !q.OneWay {
+			r.EndDate = dep.AddDate(0, 0, trip)
+		}
+
+		eg.Go(func() error {
+			resp, err := s.Search(gctx, r)
+			if err != nil || resp.Cheapest == nil {
+				return nil
+			}
+			found[i] = &domain.DatePrice{
+				DepartureDate: formatDate(r.StartDate),
+				ReturnDate:    formatDate(r.EndDate),
+				Price:         resp.Cheapest.Price,
+				Provider:      resp.Cheapest.Provider,
+				Airline:       resp.Cheapest.Airline,
+				Source:        domain.SourceLive,
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	out := make([]domain.DatePrice, 0, n)
+	for _, p := range found {
+		if p != nil {
+			out = append(out, *p)
+		}
+	}
+	return out
+}
+
+// observedDates returns the cheapest stored observation of every date pair
+// matching the query, in the query's currency
+func (s *Service) observedDates(ctx context.Context, q domain.CheapestDateQuery) []domain.DatePrice {
+	st := s.observationStore()
+	if st == nil {
+		return nil
+	}
+
+	obs, err := st.Query(ctx, history.Filter{
+		Origins:      airports.Expand(q.Origin),
+		Destinations: airports.Expand(q.Destination),
+		// the explorer prices trips for one traveler in economy
+		Cabin:      domain.CabinEconomy,
+		Passengers: domain.DefaultAdults,
+	})
+	if err != nil {
+		log.Printf("✗ Failed to read price observations: %v", err)
+		return nil
+	}
+
+	cheapest := make(map[string]domain.DatePrice)
+	for _, o := range obs {
+		if o.DepartureDate.Before(q.From) || o.DepartureDate.After(q.To) || q.OneWay != o.ReturnDate.IsZero() {
+			continue
+		}
+		if !q.OneWay {
+			days := int(o.ReturnDate.Sub(o.DepartureDate).Hours() / 24)
+			if days < q.MinDays || days > q.MaxDays {
+				continue
+			}
+		}
+		price, err := s.convert(ctx, o.Price, q.Currency)
+		if err != nil {
+			continue
+		}
+
+		p := domain.DatePrice{
+			DepartureDate: formatDate(o.DepartureDate),
+			ReturnDate:    formatDate(o.ReturnDate),
+			Price:         price,
+			Provider:      o.Provider,
+			Airline:       o.Airline,
+			Source:        domain.SourceHistorical,
+		}
+		key := p.DepartureDate + "|" + p.ReturnDate
+		if cur, ok := cheapest[key]; !ok || p.Price.Less(cur.Price) {
+			cheapest[key] = p
+		}
+	}
+	return slices.Collect(maps.Values(cheapest))
+}
+
+// rankDates converts the prices to the currency and keeps one price per date
+// pair, cheapest first. Live quotes win over historical estimates.
+func (s *Service) rankDates(ctx context.Context, prices []domain.DatePrice, currency string) []domain.DatePrice {
+	best := make(map[string]int)
+	out := make([]domain.DatePrice, 0, len(prices))
+	for _, p := range prices {
+		var err error
+		if p.Price, err = s.convert(ctx, p.Price, currency); err != nil {
+			log.Printf("✗ Dropping %s price for %s: %v", p.Provider, p.DepartureDate, err)
+			continue
+		}
+
+		key := p.DepartureDate + "|" + p.ReturnDate
+		i, seen := best[key]
+		if !seen {
+			best[key] = len(out)
+			out = append(out, p)
+			continue
+		}
+		cur := out[i]
+		live, curLive := p.Source == domain.SourceLive, cur.Source == domain.SourceLive
+		if (live && !curLive) || (live == curLive && p.Price.Less(cur.Price)) {
+			out[i] = p
+		}
+	}
+
+	slices.SortFunc(out, func(a, b domain.DatePrice) int {
+		switch {
+		case a.Price.Less(b.Price):
+			return -1
+		case b.Price.Less(a.Price):
+			return 1
+		}
+		return strings.Compare(a.DepartureDate+a.ReturnDate, b.DepartureDate+b.ReturnDate)
+	})
+	return out
+}
+
+// formatDate formats a day as YYYY-MM-DD, leaving zero dates empty
+func formatDate(d time.Time) string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format("2006-01-02")
+}
@@ -0,0 +1,192 @@
+package flights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
+)
+
+// ErrHistoryDisabled is returned by History when no observation store is configured
+var ErrHistoryDisabled = errors.New("price history is not enabled")
+
+// ErrInvalidPeriod is returned when a history period can't be parsed or is reversed
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// SetObservationStore configures where the prices seen by searches are recorded
+func (s *Service) SetObservationStore(st history.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observations = st
+}
+
+func (s *Service) observationStore() history.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.observations
+}
+
+// record stores the price of every offer of a fresh search result. Merged
+// offers record each provider's price. Failures are logged, never returned.
+func (s *Service) record(ctx context.Context, q domain.SearchQuery, resp domain.AggregatedResponse) {
+	st := s.observationStore()
+	if st == nil {
+		return
+	}
+
+	// searches without a cabin are priced in economy by every provider
+	cabin := q.Cabin
+	if cabin == "" {
+		cabin = domain.CabinEconomy
+	}
+
+	now := time.Now().UTC()
+	obs := make([]domain.Observation, 0, len(resp.Offers))
+	for _, offer := range resp.Offers {
+		o := domain.Observation{
+			Origin:        offer.Origin,
+			Destination:   offer.Destination,
+			DepartureDate: q.DepartureDate,
+			ReturnDate:    q.ReturnDate,
+			Provider:      offer.Provider,
+			Airline:       offer.Airline,
+			Cabin:         cabin,
+			Passengers:    q.Passengers(),
+			Price:         offer.Price,
+			ObservedAt:    now,
+		}
+		if o.Origin == "" || o.Destination == "" {
+			o.Origin, o.Destination = q.Origin, q.Destination
+		}
+		if len(offer.Providers) == 0 {
+			obs = append(obs, o)
+			continue
+		}
+		for _, p := range offer.Providers {
+			o.Provider, o.Price = p.Provider, p.Price
+			obs = append(obs, o)
+		}
+	}
+
+	if err := st.Record(ctx, obs); err != nil {
+		log.Printf("✗ Failed to record %d price observations: %v", len(obs), err)
+	}
+}
+
+// History returns the monthly price aggregates observed for a route, by
+// departure month unless grouped by the month the prices were seen. City
+// codes cover all of their airports. Prices are converted to the requested
+// currency; observations that can't be converted are left out.
+func (s *Service) History(ctx context.Context, req domain.HistoryRequest) (domain.PriceHistory, error) {
+	st := s.observationStore()
+	if st == nil {
+		return domain.PriceHistory{}, ErrHistoryDisabled
+	}
+
+	origin, err := normalizeLocation("origin", req.Origin)
+	if err != nil {
+		return domain.PriceHistory{}, err
+	}
+	dest, err := normalizeLocation("destination", req.Destination)
+	if err != nil {
+		return domain.PriceHistory{}, err
+	}
+	groupBy := req.GroupBy
+	if groupBy == "" {
+		groupBy = domain.HistoryByDeparture
+	}
+	byDeparture := groupBy == domain.HistoryByDeparture
+	from, to, err := historyPeriod(req.From, req.To, time.Now().UTC(), byDeparture)
+	if err != nil {
+		return domain.PriceHistory{}, err
+	}
+	currency := strings.ToUpper(req.Currency)
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	cabin := strings.ToUpper(req.Cabin)
+	if cabin == "" {
+		cabin = domain.CabinEconomy
+	}
+	passengers := req.Passengers
+	if passengers == 0 {
+		passengers = domain.DefaultAdults
+	}
+
+	filter := history.Filter{
+		Origins:      airports.Expand(origin),
+		Destinations: airports.Expand(dest),
+		Cabin:        cabin,
+		Passengers:   passengers,
+	}
+	axis := history.ByObservation
+	if byDeparture {
+		filter.DepartFrom, filter.DepartTo = from, to.AddDate(0, 1, 0)
+		axis = history.ByDeparture
+	} else {
+		filter.From, filter.To = from, to.AddDate(0, 1, 0)
+	}
+	obs, err := st.Query(ctx, filter)
+	if err != nil {
+		return domain.PriceHistory{}, fmt.Errorf("history: %w", err)
+	}
+
+	same := make([]domain.Observation, 0, len(obs))
+	for _, o := range obs {
+		if o.Price, err = s.convert(ctx, o.Price, currency); err != nil {
+			continue
+		}
+		same = append(same, o)
+	}
+
+	return domain.PriceHistory{
+		Origin:      origin,
+		Destination: dest,
+		GroupBy:     groupBy,
+		From:        from.Format("2006-01"),
+		To:          to.Format("2006-01"),
+		Cabin:       cabin,
+		Passengers:  passengers,
+		Currency:    currency,
+		History:     history.Monthly(same, axis),
+	}, nil
+}
+
+// historyPeriod parses the YYYY-MM bounds. Without them, departures default to
+// the twelve months from now and observations to the twelve months up to now.
+func historyPeriod(fromRaw, toRaw string, now time.Time, ahead bool) (from, to time.Time, err error) {
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if fromRaw != "" {
+		if from, err = time.Parse("2006-01", fromRaw); err != nil {
+			return from, to, fmt.Errorf("%w: from %q", ErrInvalidPeriod, fromRaw)
+		}
+	}
+	if toRaw != "" {
+		if to, err = time.Parse("2006-01", toRaw); err != nil {
+			return from, to, fmt.Errorf("%w: to %q", ErrInvalidPeriod, toRaw)
+		}
+	}
+
+	switch {
+	case fromRaw == "" && toRaw == "" && ahead:
+		from, to = month, month.AddDate(0, 11, 0)
+	case fromRaw == "" && toRaw == "":
+		from, to = month.AddDate(0, -11, 0), month
+	case fromRaw == "":
+		from = to.AddDate(0, -11, 0)
+	case toRaw == "" && ahead:
+		to = from.AddDate(0, 11, 0)
+	case toRaw == "":
+		to = month
+	}
+	if to.Before(from) {
+		return from, to, fmt.Errorf("%w: %s is after %s", ErrInvalidPeriod, from.Format("2006-01"), to.Format("2006-01"))
+	}
+	return from, to, nil
+}
@@ -0,0 +1,205 @@
+package flights
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+// meetupConcurrency bounds the origin/destination searches run at once
+const meetupConcurrency = 4
+
+// maxMeetupSearches bounds the provider searches of a meet-up: every airport
+// pair of every origin/destination route
+const maxMeetupSearches = 60
+
+// route is one origin/destination pair of a meet-up search
+type route struct{ origin, destination string }
+
+// Meetup searches every origin/destination pair concurrently through Search
+// and ranks the destinations by the attendees' combined cheapest fare or by
+// the longest travel time among them. Each pair is searched once, however
+// many attendees share its origin.
+func (s *Service) Meetup(ctx context.Context, q domain.MeetupQuery) (domain.MeetupResponse, error) {
+	var err error
+	for i, o := range q.Origins {
+		if q.Origins[i], err = normalizeLocation("origin", o); err != nil {
+			return domain.MeetupResponse{}, err
+		}
+	}
+	for i, d := range q.Destinations {
+		if q.Destinations[i], err = normalizeLocation("destination", d); err != nil {
+			return domain.MeetupResponse{}, err
+		}
+	}
+	q.Destinations = unique(q.Destinations)
+
+	best := make(map[route]*domain.AttendeeQuote)
+	type search struct {
+		aq  *domain.AttendeeQuote
+		req domain.SearchRequest
+	}
+	var searches []search
+	total := 0
+	for _, dest := range q.Destinations {
+		for _, origin := range unique(q.Origins) {
+			aq := &domain.AttendeeQuote{Origin: origin}
+			best[route{origin, dest}] = aq
+			if sameLocation(origin, dest) {
+				aq.Local = true
+				continue
+			}
+
+			req := domain.SearchRequest{
+				Origin:      origin,
+				Destination: dest,
+				StartDate:   q.DepartureDate,
+				EndDate:     q.ReturnDate,
+				Cabin:       q.Cabin,
+				NonStop:     q.NonStop,
+				Currency:    q.Currency,
+			}
+			sp, err := plan(req)
+			if err != nil {
+				return domain.MeetupResponse{}, err
+			}
+			total += len(sp.pairs)
+			searches = append(searches, search{aq, req})
+		}
+	}
+	if total > maxMeetupSearches {
+		return domain.MeetupResponse{}, fmt.Errorf("%w: %d searches (%d routes with their airport pairs), at most %d; use fewer origins, destinations or city codes",
+			ErrSearchTooLarge, total, len(searches), maxMeetupSearches)
+	}
+
+	eg, gctx := errgroup.WithContext(ctx)
+	eg.SetLimit(meetupConcurrency)
+	for _, sr := range searches {
+		eg.Go(func() error {
+			resp, err := s.Search(gctx, sr.req)
+			pick := resp.Cheapest
+			if q.RankBy == domain.RankByDuration {
+				pick = resp.Fastest
+			}
+			switch {
+			case err != nil:
+				sr.aq.Error = err.Error()
+			case pick == nil:
+				sr.aq.Error = "no offers"
+			default:
+				sr.aq.Quote = pick
+			}
+			return nil
+		})
+	}
+	_ = eg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return domain.MeetupResponse{}, err
+	}
+
+	options := make([]domain.MeetupOption, 0, len(q.Destinations))
+	for _, dest := range q.Destinations {
+		options = append(options, meetupOption(dest, q, best))
+	}
+	slices.SortStableFunc(options, func(a, b domain.MeetupOption) int {
+		if a.Complete != b.Complete {
+			if a.Complete {
+				return -1
+			}
+			return 1
+		}
+		byPrice, byDuration := comparePrice(a.TotalPrice, b.TotalPrice), cmp.Compare(a.MaxDuration, b.MaxDuration)
+		if q.RankBy == domain.RankByDuration {
+			return cmp.Or(byDuration, byPrice)
+		}
+		return cmp.Or(byPrice, byDuration)
+	})
+
+	return domain.MeetupResponse{
+		Origins:      q.Origins,
+		RankBy:       q.RankBy,
+		Currency:     q.Currency,
+		Destinations: options,
+	}, nil
+}
+
+// meetupOption gathers every attendee's quote to the destination and totals them
+func meetupOption(dest string, q domain.MeetupQuery, best map[route]*domain.AttendeeQuote) domain.MeetupOption {
+	opt := domain.MeetupOption{
+		Destination: dest,
+		Complete:    true,
+		Attendees:   make([]domain.AttendeeQuote, len(q.Origins)),
+	}
+	_, opt.City, opt.Country = place(dest)
+
+	total := domain.NewMoney(0, q.Currency)
+	priced := true
+	for i, origin := range q.Origins {
+		aq := *best[route{origin, dest}]
+		opt.Attendees[i] = aq
+		if aq.Local {
+			continue
+		}
+		if aq.Quote == nil {
+			opt.Complete = false
+			continue
+		}
+		opt.MaxDuration = max(opt.MaxDuration, aq.Quote.Duration)
+		// prices that couldn't be converted can't be added up
+		var err error
+		if total, err = total.Add(aq.Quote.Price); err != nil {
+			priced = false
+		}
+	}
+	if opt.Complete && priced {
+		opt.TotalPrice = &total
+	}
+	return opt
+}
+
+// comparePrice orders prices ascending, missing ones last
+func comparePrice(a, b *domain.Money) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	case a.Less(*b):
+		return -1
+	case b.Less(*a):
+		return 1
+	}
+	return 0
+}
+
+// sameLocation reports whether two airport or city codes share an airport,
+// e.g. LHR and LON
+func sameLocation(a, b string) bool {
+	if strings.EqualFold(a, b) {
+		return true
+	}
+	bs := airports.Expand(b)
+	return slices.ContainsFunc(airports.Expand(a), func(c string) bool {
+		return slices.Contains(bs, c)
+	})
+}
+
+// unique returns the codes without repetitions, in order
+func unique(codes []string) []string {
+	out := make([]string, 0, len(codes))
+	for _, c := range codes {
+		if !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -5,11 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
 	"github.com/poportss/go-challenge-flight-price/internal/providers"
 	"golang.org/x/sync/errgroup"
 )
@@ -19,18 +23,42 @@ type Service struct {
 	providers []providers.Provider
 	timeout   time.Duration
 	cache     Cache
+	rates     fx.RateSource
+
+	observations history.Store // nil disables price history
+	inflight     coalescer     // searches in flight, shared by identical requests
 }
 
 func NewService(p []providers.Provider, timeout time.Duration, cache Cache) *Service {
 	return &Service{providers: p, timeout: timeout, cache: cache}
 }
 
+// ErrDuplicateProvider is returned when a provider with the same name is already registered
+var ErrDuplicateProvider = errors.New("provider already registered")
+
 // AddProvider dynamically adds a new provider to the service
-func (s *Service) AddProvider(p providers.Provider) {
+func (s *Service) AddProvider(p providers.Provider) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	for _, existing := range s.providers {
+		if existing.Name() == p.Name() {
+			return fmt.Errorf("%w: %s", ErrDuplicateProvider, p.Name())
+		}
+	}
 	s.providers = append(s.providers, p)
 	log.Printf("✓ Provider %s added dynamically", p.Name())
+	return nil
+}
+
+// ProviderNames returns the names of the registered providers
+func (s *Service) ProviderNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	names := make([]string, 0, len(s.providers))
+	for _, p := range s.providers {
+		names = append(names, p.Name())
+	}
+	return names
 }
 
 // RemoveProvider removes a provider by its name
@@ -47,50 +75,395 @@ func (s *Service) RemoveProvider(name string) {
 	}
 }
 
+// ErrInvalidLocation is returned when an origin or destination is not a known IATA code
+var ErrInvalidLocation = errors.New("invalid location")
+
+// ErrMultiCityUnsupported is returned when no registered provider can price multi-city itineraries
+var ErrMultiCityUnsupported = errors.New("no provider supports multi-city search")
+
 // Search queries all active providers concurrently and aggregates the results
 func (s *Service) Search(ctx context.Context, req domain.SearchRequest) (domain.AggregatedResponse, error) {
-	cacheKey := fmt.Sprintf("%s|%s|%s|%s",
-		req.Origin,
-		req.Destination,
-		req.StartDate.Format("2006-01-02"),
-		req.EndDate.Format("2006-01-02"))
+	return s.SearchStream(ctx, req, nil)
+}
+
+// SearchStream works like Search and also calls onResult with each provider's
+// quotes as soon as that provider answers. onResult is called from a single
+// goroutine, and not at all when the response comes from the cache.
+//
+// Without onResult, concurrent identical searches share one provider fan-out:
+// every caller gets its result, and the providers are only canceled once all
+// of the callers have gone away.
+func (s *Service) SearchStream(ctx context.Context, req domain.SearchRequest, onResult func(domain.ProviderResult)) (domain.AggregatedResponse, error) {
+	sp, err := plan(req)
+	if err != nil {
+		return domain.AggregatedResponse{}, err
+	}
+	q := sp.query
+
+	cacheKey := q.Key()
+	if req.NearbyKm > 0 {
+		cacheKey += fmt.Sprintf("|nearby=%g", req.NearbyKm)
+	}
+
+	// provider results can't be replayed to other callers, so streaming
+	// searches run their own fan-out instead of joining one in flight
+	return s.cached(ctx, cacheKey, onResult == nil, func(ctx context.Context) (domain.AggregatedResponse, error) {
+		resp, err := s.fanOut(ctx, s.snapshot(), onResult, func(ctx context.Context, p providers.Provider) ([]domain.Quote, error) {
+			qs, err := searchPairs(ctx, p, q, sp.pairs)
+			if err != nil {
+				return nil, err
+			}
+			if q.NonStop {
+				qs = nonStopOnly(qs)
+			}
+			return s.inCurrency(ctx, qs, q.Currency)
+		})
+		if err != nil {
+			return resp, err
+		}
+		// a search abandoned by every caller may hold partial results
+		if ctx.Err() == nil {
+			s.record(ctx, q, resp)
+		}
+		resp.SkippedPairs = sp.skipped
+		return withAirports(resp, q.Origin, q.Destination), nil
+	})
+}
+
+// Validate checks the origin and destination of a search without calling any
+// provider, returning an ErrInvalidLocation error when they can't be searched
+func (s *Service) Validate(req domain.SearchRequest) error {
+	_, err := plan(req)
+	return err
+}
+
+// searchPlan is a normalized query and the airport pairs to search for it
+type searchPlan struct {
+	query   domain.SearchQuery
+	pairs   []airportPair
+	skipped int // airport pairs left out by maxAirportPairs
+}
+
+// plan normalizes the query locations and lists the airport pairs to search
+func plan(req domain.SearchRequest) (searchPlan, error) {
+	q := req.Query()
+	if err := q.Validate(); err != nil {
+		return searchPlan{}, err
+	}
+
+	var err error
+	if q.Origin, err = normalizeLocation("origin", q.Origin); err != nil {
+		return searchPlan{}, err
+	}
+	if q.Destination, err = normalizeLocation("destination", q.Destination); err != nil {
+		return searchPlan{}, err
+	}
+	if q.Origin == q.Destination {
+		return searchPlan{}, fmt.Errorf("%w: origin and destination are the same", ErrInvalidLocation)
+	}
+
+	origins, detourKm := expandOrigin(q.Origin, req.NearbyKm)
+	pairs, skipped := airportPairs(origins, detourKm, airports.Expand(q.Destination))
+	if len(pairs) == 0 {
+		return searchPlan{}, fmt.Errorf("%w: no airport pairs to search", ErrInvalidLocation)
+	}
+	return searchPlan{query: q, pairs: pairs, skipped: skipped}, nil
+}
+
+// maxAirportPairs bounds the fan-out of metro-area and nearby-airport searches
+const maxAirportPairs = 20
+
+type airportPair struct{ origin, destination string }
+
+// expandOrigin returns the airports of the origin plus those within nearbyKm
+// of any of them, with how far each added airport is from the closest of the
+// origin's own
+func expandOrigin(origin string, nearbyKm float64) ([]string, map[string]float64) {
+	codes := airports.Expand(origin)
+	detourKm := make(map[string]float64, len(codes))
+	for _, c := range codes {
+		detourKm[c] = 0
+	}
+	if nearbyKm <= 0 {
+		return codes, detourKm
+	}
+	for _, c := range codes {
+		from, _ := airports.Lookup(c)
+		for _, a := range airports.Nearby(c, nearbyKm) {
+			km := airports.DistanceKm(from, a)
+			if d, seen := detourKm[a.IATA]; !seen {
+				codes = append(codes, a.IATA)
+				detourKm[a.IATA] = km
+			} else if km < d {
+				detourKm[a.IATA] = km
+			}
+		}
+	}
+	return codes, detourKm
+}
+
+// airportPairs combines every origin with every destination, skipping
+// same-airport pairs. Past maxAirportPairs it keeps the pairs closest to what
+// was asked for: the origin's own airports before nearby ones, then the
+// shortest routes. It also returns the number of pairs left out.
+func airportPairs(origins []string, detourKm map[string]float64, destinations []string) ([]airportPair, int) {
+	type ranked struct {
+		pair              airportPair
+		detourKm, routeKm float64
+	}
+	all := make([]ranked, 0, len(origins)*len(destinations))
+	for _, o := range origins {
+		from, fromOK := airports.Lookup(o)
+		for _, d := range destinations {
+			if o == d {
+				continue
+			}
+			r := ranked{pair: airportPair{origin: o, destination: d}, detourKm: detourKm[o]}
+			if to, ok := airports.Lookup(d); ok && fromOK {
+				r.routeKm = airports.DistanceKm(from, to)
+			}
+			all = append(all, r)
+		}
+	}
+	if len(all) > maxAirportPairs {
+		sort.SliceStable(all, func(i, j int) bool {
+			if all[i].detourKm != all[j].detourKm {
+				return all[i].detourKm < all[j].detourKm
+			}
+			return all[i].routeKm < all[j].routeKm
+		})
+	}
+
+	n := min(len(all), maxAirportPairs)
+	pairs := make([]airportPair, n)
+	for i := range n {
+		pairs[i] = all[i].pair
+	}
+	return pairs, len(all) - n
+}
+
+// searchPairs runs the provider search for every airport pair concurrently and
+// merges the quotes; it only fails when every pair failed
+func searchPairs(ctx context.Context, p providers.Provider, q domain.SearchQuery, pairs []airportPair) ([]domain.Quote, error) {
+	if len(pairs) == 1 {
+		return p.Search(ctx, withPair(q, pairs[0]))
+	}
+
+	var (
+		mu   sync.Mutex
+		all  []domain.Quote
+		errs []error
+		wg   sync.WaitGroup
+	)
+	for _, pair := range pairs {
+		wg.Add(1)
+		go func(pq domain.SearchQuery) {
+			defer wg.Done()
+			qs, err := p.Search(ctx, pq)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s→%s: %w", pq.Origin, pq.Destination, err))
+				return
+			}
+			all = append(all, qs...)
+		}(withPair(q, pair))
+	}
+	wg.Wait()
+
+	if len(all) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return all, nil
+}
 
+func withPair(q domain.SearchQuery, pair airportPair) domain.SearchQuery {
+	q.Origin, q.Destination = pair.origin, pair.destination
+	return q
+}
+
+// SearchMultiCity queries every provider able to price multi-city itineraries
+// concurrently and aggregates the results
+func (s *Service) SearchMultiCity(ctx context.Context, q domain.MultiCityQuery) (domain.AggregatedResponse, error) {
+	legs := make([]domain.Leg, len(q.Legs))
+	codes := make([]string, 0, 2*len(q.Legs))
+	for i, l := range q.Legs {
+		var err error
+		if l.Origin, err = normalizeLocation(fmt.Sprintf("leg %d origin", i+1), l.Origin); err != nil {
+			return domain.AggregatedResponse{}, err
+		}
+		if l.Destination, err = normalizeLocation(fmt.Sprintf("leg %d destination", i+1), l.Destination); err != nil {
+			return domain.AggregatedResponse{}, err
+		}
+		legs[i] = l
+		codes = append(codes, l.Origin, l.Destination)
+	}
+	q.Legs = legs
+
+	provs := s.snapshot()
+	capable := false
+	for _, p := range provs {
+		if _, ok := p.(providers.MultiCityProvider); ok {
+			capable = true
+		}
+	}
+	if !capable {
+		return domain.AggregatedResponse{}, ErrMultiCityUnsupported
+	}
+
+	return s.cached(ctx, q.Key(), true, func(ctx context.Context) (domain.AggregatedResponse, error) {
+		resp, err := s.fanOut(ctx, provs, nil, func(ctx context.Context, p providers.Provider) ([]domain.Quote, error) {
+			mp, ok := p.(providers.MultiCityProvider)
+			if !ok {
+				return nil, fmt.Errorf("%w: multi-city search not supported", errSkipped)
+			}
+			qs, err := mp.SearchMultiCity(ctx, q)
+			if err != nil {
+				return nil, err
+			}
+			return s.inCurrency(ctx, qs, q.Currency)
+		})
+		if err != nil {
+			return resp, err
+		}
+		return withAirports(resp, codes...), nil
+	})
+}
+
+// normalizeLocation validates an origin or destination against the airport reference data
+func normalizeLocation(field, code string) (string, error) {
+	c, err := airports.Normalize(code)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrInvalidLocation, field, err)
+	}
+	return c, nil
+}
+
+// withAirports attaches reference data for the given codes and every airport
+// the offers touch
+func withAirports(resp domain.AggregatedResponse, codes ...string) domain.AggregatedResponse {
+	info := make(map[string]domain.Airport)
+	add := func(code string) {
+		if _, done := info[code]; done {
+			return
+		}
+		if a, ok := airports.Lookup(code); ok {
+			info[code] = a
+		}
+		// city codes stand for all of their airports
+		if as, ok := airports.CityAirports(code); ok {
+			for _, a := range as {
+				info[a.IATA] = a
+			}
+		}
+	}
+
+	for _, c := range codes {
+		add(c)
+	}
+	for _, q := range resp.Offers {
+		add(q.Origin)
+		add(q.Destination)
+		for _, it := range q.Legs() {
+			for _, seg := range it.Segments {
+				add(seg.Origin)
+				add(seg.Destination)
+			}
+		}
+	}
+
+	resp.Airports = info
+	return resp
+}
+
+// nonStopOnly drops quotes whose segments show a connection, in case a
+// provider ignored the non-stop filter
+func nonStopOnly(qs []domain.Quote) []domain.Quote {
+	out := make([]domain.Quote, 0, len(qs))
+	for _, q := range qs {
+		if q.Stops() <= 0 {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// snapshot returns a copy of the provider list that is safe to iterate without the lock
+func (s *Service) snapshot() []providers.Provider {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	providersCopy := make([]providers.Provider, len(s.providers))
+	copy(providersCopy, s.providers)
+	return providersCopy
+}
+
+// cached returns the response stored under key or computes and stores it.
+// With coalesce, concurrent misses on the same key share one computation.
+func (s *Service) cached(ctx context.Context, cacheKey string, coalesce bool, compute func(context.Context) (domain.AggregatedResponse, error)) (domain.AggregatedResponse, error) {
 	// Try fetching from cache first
-	if v, ok := s.cache.Get(cacheKey); ok {
+	if resp, ok := s.cache.Get(cacheKey); ok {
 		log.Printf("✓ Cache HIT for %s", cacheKey)
-		return v.(domain.AggregatedResponse), nil
+		return resp, nil
 	}
 
 	log.Printf("✗ Cache MISS for %s", cacheKey)
 
+	store := func(ctx context.Context) (domain.AggregatedResponse, error) {
+		resp, err := compute(ctx)
+		if err != nil {
+			return resp, err
+		}
+		if ctx.Err() != nil {
+			// every caller gave up: the providers still running were canceled,
+			// so the response is incomplete and must not be served to others
+			return resp, nil
+		}
+
+		ttl := cacheTTL
+		if resp.Partial {
+			ttl = partialCacheTTL
+		}
+		s.cache.Set(cacheKey, resp, ttl)
+		log.Printf("✓ Response cached: %s", cacheKey)
+
+		return resp, nil
+	}
+
+	if !coalesce {
+		return store(ctx)
+	}
+	return s.inflight.do(ctx, cacheKey, store)
+}
+
+// fanOut calls search on every provider concurrently, aggregates the quotes
+// and reports each provider's outcome. The statuses are set even on error.
+// onResult, when not nil, receives each provider's result as it arrives.
+func (s *Service) fanOut(ctx context.Context, provs []providers.Provider, onResult func(domain.ProviderResult), search func(context.Context, providers.Provider) ([]domain.Quote, error)) (domain.AggregatedResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, s.timeout)
 	defer cancel()
 
-	// Lock for safe concurrent access to the provider list
-	s.mu.RLock()
-	providersCopy := make([]providers.Provider, len(s.providers))
-	copy(providersCopy, s.providers)
-	s.mu.RUnlock()
-
 	type result struct {
-		provider string
-		quotes   []domain.Quote
-		err      error
+		index  int
+		quotes []domain.Quote
+		status domain.ProviderStatus
 	}
-	resCh := make(chan result, len(providersCopy))
+	resCh := make(chan result, len(provs))
 
 	eg, ctx := errgroup.WithContext(ctx)
-	for _, p := range providersCopy {
+	for i, p := range provs {
 		prov := p
 		eg.Go(func() error {
 			log.Printf("→ Fetching from %s...", prov.Name())
-			qs, err := prov.Search(ctx, req.Origin, req.Destination, req.StartDate, req.EndDate)
-			if err != nil {
+			start := time.Now()
+			qs, err := search(ctx, prov)
+			switch {
+			case errors.Is(err, errSkipped):
+			case err != nil:
 				log.Printf("✗ Error from provider %s: %v", prov.Name(), err)
-			} else {
+			default:
 				log.Printf("✓ Provider %s returned %d quotes", prov.Name(), len(qs))
 			}
-			resCh <- result{provider: prov.Name(), quotes: qs, err: err}
+			resCh <- result{index: i, quotes: qs, status: providerStatus(prov.Name(), len(qs), time.Since(start), err)}
 			return nil
 		})
 	}
@@ -101,24 +474,44 @@ func (s *Service) Search(ctx context.Context, req domain.SearchRequest) (domain.
 	}()
 
 	all := make([]domain.Quote, 0, 16)
-	var atLeastOne bool
+	statuses := make([]domain.ProviderStatus, len(provs))
 	for r := range resCh {
-		if r.err == nil && len(r.quotes) > 0 {
+		statuses[r.index] = r.status
+		offers := []domain.Quote{}
+		if r.status.Status == domain.ProviderOK {
 			all = append(all, r.quotes...)
-			atLeastOne = true
+			offers = append(offers, r.quotes...)
 		}
+		if onResult != nil {
+			onResult(domain.ProviderResult{ProviderStatus: r.status, Offers: offers})
+		}
+	}
+
+	resp, err := aggregate(all)
+	// providers that answered with no flights are a valid, empty result
+	if len(all) == 0 && slices.ContainsFunc(statuses, func(st domain.ProviderStatus) bool { return st.Status == domain.ProviderOK }) {
+		resp, err = domain.AggregatedResponse{Offers: []domain.Quote{}}, nil
 	}
+	resp.Providers = statuses
+	resp.Partial = partial(statuses)
+	return resp, err
+}
 
-	if !atLeastOne {
+// aggregate ranks the quotes and picks the cheapest and fastest offers
+func aggregate(all []domain.Quote) (domain.AggregatedResponse, error) {
+	if len(all) == 0 {
 		return domain.AggregatedResponse{}, errors.New("no providers returned valid quotes")
 	}
 
+	// Merge identical itineraries sold by several providers
+	all = dedupe(all)
+
 	// Sort by price, then by duration
 	sort.Slice(all, func(i, j int) bool {
 		if all[i].Price == all[j].Price {
 			return all[i].Duration < all[j].Duration
 		}
-		return all[i].Price < all[j].Price
+		return all[i].Price.Less(all[j].Price)
 	})
 
 	// The cheapest offer is the first
@@ -132,15 +525,9 @@ func (s *Service) Search(ctx context.Context, req domain.SearchRequest) (domain.
 		}
 	}
 
-	resp := domain.AggregatedResponse{
+	return domain.AggregatedResponse{
 		Cheapest: &cheapest,
 		Fastest:  &fastest,
 		Offers:   all,
-	}
-
-	// Store the response in cache for 30 seconds
-	s.cache.Set(cacheKey, resp, 30*time.Second)
-	log.Printf("✓ Response cached: %s", cacheKey)
-
-	return resp, nil
+	}, nil
 }
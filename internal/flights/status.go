@@ -0,0 +1,65 @@
+package flights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// errSkipped marks providers that can't serve a search, so they are reported
+// without counting as a failure
+var errSkipped = errors.New("skipped")
+
+// providerStatus describes the outcome of one provider call
+func providerStatus(name string, quotes int, latency time.Duration, err error) domain.ProviderStatus {
+	st := domain.ProviderStatus{
+		Name:      name,
+		Status:    domain.ProviderOK,
+		LatencyMs: latency.Milliseconds(),
+		Quotes:    quotes,
+	}
+	if err == nil {
+		return st
+	}
+
+	st.Quotes = 0
+	st.Status, st.Error = classify(err)
+	return st
+}
+
+// classify maps a provider error to a status and a message that is safe to
+// return to clients: raw errors may carry response bodies or URLs with API keys
+func classify(err error) (status, message string) {
+	var statusErr *providers.StatusError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, errSkipped):
+		return domain.ProviderSkipped, strings.TrimPrefix(err.Error(), errSkipped.Error()+": ")
+	case errors.Is(err, context.DeadlineExceeded):
+		return domain.ProviderTimeout, "provider timed out"
+	case errors.Is(err, context.Canceled):
+		return domain.ProviderError, "search canceled"
+	case errors.As(err, &statusErr):
+		return domain.ProviderError, fmt.Sprintf("provider returned status %d", statusErr.Code)
+	case errors.As(err, &urlErr):
+		return domain.ProviderError, "provider unreachable"
+	default:
+		return domain.ProviderError, "provider returned an invalid response"
+	}
+}
+
+// partial reports whether any provider failed or timed out
+func partial(statuses []domain.ProviderStatus) bool {
+	for _, st := range statuses {
+		if st.Status == domain.ProviderError || st.Status == domain.ProviderTimeout {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// FileSource serves rates from a JSON file loaded at startup
+type FileSource struct {
+	table Table
+}
+
+func NewFileSource(path string) (*FileSource, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("fx: read %s: %w", path, err)
+	}
+	var t Table
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return nil, fmt.Errorf("fx: parse %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("%w in %s", err, path)
+	}
+	return &FileSource{table: t}, nil
+}
+
+func (f *FileSource) Rate(ctx context.Context, from, to string) (float64, error) {
+	return f.table.Rate(from, to)
+}
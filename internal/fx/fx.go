@@ -0,0 +1,85 @@
+// Package fx provides foreign exchange rates used to compare prices across providers.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// ErrUnknownCurrency is returned when a rate for a currency is not available
+var ErrUnknownCurrency = errors.New("fx: unknown currency")
+
+// RateSource returns exchange rates
+type RateSource interface {
+	// Rate returns how many units of `to` one unit of `from` buys
+	Rate(ctx context.Context, from, to string) (float64, error)
+}
+
+// Table is a set of rates relative to a base currency, in the format served
+// by most rates APIs: {"base":"USD","rates":{"EUR":0.92,...}}
+type Table struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Rate converts through the base currency
+func (t Table) Rate(from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+	f, err := t.baseRate(from)
+	if err != nil {
+		return 0, err
+	}
+	r, err := t.baseRate(to)
+	if err != nil {
+		return 0, err
+	}
+	return r / f, nil
+}
+
+// validate rejects tables that can't convert anything, such as the error
+// bodies some APIs send with a 200
+func (t Table) validate() error {
+	if strings.TrimSpace(t.Base) == "" {
+		return errors.New("fx: rates table has no base currency")
+	}
+	if len(t.Rates) == 0 {
+		return errors.New("fx: rates table has no rates")
+	}
+	for currency, r := range t.Rates {
+		if r <= 0 || math.IsInf(r, 0) || math.IsNaN(r) {
+			return fmt.Errorf("fx: invalid rate %v for %s", r, currency)
+		}
+	}
+	return nil
+}
+
+func (t Table) baseRate(currency string) (float64, error) {
+	if currency == strings.ToUpper(t.Base) {
+		return 1, nil
+	}
+	r, ok := t.Rates[currency]
+	if !ok || r <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	return r, nil
+}
+
+// Convert converts an amount into another currency using the source
+func Convert(ctx context.Context, src RateSource, m domain.Money, to string) (domain.Money, error) {
+	if strings.EqualFold(m.Currency, to) {
+		return m, nil
+	}
+	rate, err := src.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	return m.Convert(rate, to)
+}
@@ -0,0 +1,134 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Retry delays after a failed refresh, doubled on every failure in a row
+const (
+	minRetryDelay = 1 * time.Second
+	maxRetryDelay = 5 * time.Minute
+)
+
+// HTTPSource fetches a rates table from an HTTP API and caches it for ttl
+type HTTPSource struct {
+	client *http.Client
+	url    string
+	ttl    time.Duration
+
+	refresh singleflight.Group
+
+	mu         sync.Mutex
+	table      Table
+	fetched    time.Time
+	err        error         // last refresh failure
+	retryAt    time.Time     // no refresh is attempted before
+	retryDelay time.Duration // wait after the next failure
+}
+
+func NewHTTPSource(client *http.Client, url string, ttl time.Duration) *HTTPSource {
+	return &HTTPSource{client: client, url: url, ttl: ttl}
+}
+
+func (h *HTTPSource) Rate(ctx context.Context, from, to string) (float64, error) {
+	t, err := h.current(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return t.Rate(from, to)
+}
+
+// current returns the cached table, refreshing it once expired. Concurrent
+// callers share one refresh, made without holding the lock. A stale table is
+// kept when the refresh fails so a flaky rates API doesn't break searches, and
+// failed refreshes are retried with exponential backoff.
+func (h *HTTPSource) current(ctx context.Context) (Table, error) {
+	h.mu.Lock()
+	table, fetched, err, retryAt := h.table, h.fetched, h.err, h.retryAt
+	h.mu.Unlock()
+
+	switch {
+	case !fetched.IsZero() && time.Since(fetched) < h.ttl:
+		return table, nil
+	case time.Now().Before(retryAt):
+		return staleOr(table, fetched, err)
+	}
+
+	ch := h.refresh.DoChan("rates", func() (any, error) {
+		// shared by every waiting caller, so one leaving doesn't cancel it
+		t, err := h.fetch(context.WithoutCancel(ctx))
+		h.done(t, err)
+		return t, err
+	})
+	select {
+	case <-ctx.Done():
+		return Table{}, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return staleOr(table, fetched, r.Err)
+		}
+		return r.Val.(Table), nil
+	}
+}
+
+// done records the outcome of a refresh
+func (h *HTTPSource) done(t Table, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err == nil {
+		h.table, h.fetched, h.err, h.retryAt, h.retryDelay = t, time.Now(), nil, time.Time{}, 0
+		return
+	}
+	h.retryDelay = min(max(2*h.retryDelay, minRetryDelay), maxRetryDelay)
+	h.err, h.retryAt = err, time.Now().Add(h.retryDelay)
+}
+
+// staleOr returns the last good table, if there is one, or err
+func staleOr(t Table, fetched time.Time, err error) (Table, error) {
+	if fetched.IsZero() {
+		return Table{}, err
+	}
+	return t, nil
+}
+
+func (h *HTTPSource) fetch(ctx context.Context) (Table, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return Table{}, fmt.Errorf("fx: build request failed: %w", err)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Table{}, fmt.Errorf("fx: http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return Table{}, fmt.Errorf("fx: unexpected status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var t Table
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return Table{}, fmt.Errorf("fx: decode failed: %w", err)
+	}
+	if err := t.validate(); err != nil {
+		return Table{}, err
+	}
+	return t, nil
+}
+
+// StubHandler serves a fixed table like a rates API would, for local runs and tests
+func StubHandler(t Table) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(t)
+	})
+}
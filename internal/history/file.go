@@ -0,0 +1,211 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// compactEvery is how often a file store with a retention drops old observations
+const compactEvery = 24 * time.Hour
+
+// FileStore appends observations to a JSON-lines file and serves queries from
+// memory; the file is replayed on open so history survives restarts. Every
+// observation within the retention is held in memory, a few hundred bytes
+// each, so the retention is what bounds its memory use: without one the
+// store grows for as long as searches are made.
+type FileStore struct {
+	mem       *MemoryStore
+	path      string
+	retention time.Duration // how long observations are kept, zero for ever
+
+	mu sync.Mutex
+	f  *os.File
+
+	stop chan struct{} // nil when there is no retention
+	done chan struct{}
+}
+
+// OpenFileStore loads the observations already in path, creating it if needed.
+// With a retention, observations older than it are dropped on open and every
+// day after, and the file is rewritten without them.
+func OpenFileStore(path string, retention time.Duration) (*FileStore, error) {
+	s := &FileStore{mem: NewMemoryStore(), path: path, retention: retention}
+
+	stale := 0
+	if f, err := os.Open(path); err == nil {
+		stale, err = replay(f, s.mem, s.cutoff())
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("history: read %s: %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("history: open %s: %w", path, err)
+	}
+
+	if stale > 0 {
+		if err := s.rewrite(); err != nil {
+			return nil, err
+		}
+	} else if err := s.reopen(); err != nil {
+		return nil, err
+	}
+
+	if retention > 0 {
+		s.stop, s.done = make(chan struct{}), make(chan struct{})
+		go s.compactLoop()
+	}
+	return s, nil
+}
+
+// replay loads the observations of f seen after cutoff and counts the others
+func replay(f *os.File, mem *MemoryStore, cutoff time.Time) (stale int, err error) {
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	var obs []domain.Observation
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var o domain.Observation
+		if err := json.Unmarshal(sc.Bytes(), &o); err != nil {
+			return 0, fmt.Errorf("line %d: %w", line, err)
+		}
+		if o.ObservedAt.Before(cutoff) {
+			stale++
+			continue
+		}
+		obs = append(obs, o)
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return stale, mem.Record(context.Background(), obs)
+}
+
+func (s *FileStore) Record(ctx context.Context, obs []domain.Observation) error {
+	var buf []byte
+	for _, o := range obs {
+		line, err := json.Marshal(o)
+		if err != nil {
+			return fmt.Errorf("history: encode observation: %w", err)
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// a single write keeps the batch on whole lines
+	if _, err := s.f.Write(buf); err != nil {
+		return fmt.Errorf("history: write: %w", err)
+	}
+	return s.mem.Record(ctx, obs)
+}
+
+func (s *FileStore) Query(ctx context.Context, f Filter) ([]domain.Observation, error) {
+	return s.mem.Query(ctx, f)
+}
+
+// Compact drops the observations older than the retention and rewrites the
+// file with the others
+func (s *FileStore) Compact() error {
+	if s.retention <= 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mem.Prune(s.cutoff()) == 0 {
+		return nil
+	}
+	return s.rewrite()
+}
+
+// Close stops the compaction and closes the underlying file
+func (s *FileStore) Close() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+func (s *FileStore) compactLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(compactEvery)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Compact(); err != nil {
+				log.Printf("✗ Failed to compact price history: %v", err)
+			}
+		}
+	}
+}
+
+// cutoff is the time before which observations are dropped
+func (s *FileStore) cutoff() time.Time {
+	if s.retention <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-s.retention)
+}
+
+// rewrite replaces the file with the observations in memory, through a
+// temporary file renamed over it so a crash never loses the history; the
+// caller holds the lock, or is opening the store
+func (s *FileStore) rewrite() error {
+	obs, _ := s.mem.Query(context.Background(), Filter{})
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return fmt.Errorf("history: compact: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, o := range obs {
+		if err := enc.Encode(o); err != nil {
+			tmp.Close()
+			return fmt.Errorf("history: compact: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("history: compact: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("history: compact: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("history: compact: %w", err)
+	}
+	return s.reopen()
+}
+
+// reopen opens the file for appending, closing the previous handle
+func (s *FileStore) reopen() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("history: open %s: %w", s.path, err)
+	}
+	if s.f != nil {
+		s.f.Close()
+	}
+	s.f = f
+	return nil
+}
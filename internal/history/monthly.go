@@ -0,0 +1,51 @@
+package history
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// ByDeparture and ByObservation pick the date observations are grouped by
+func ByDeparture(o domain.Observation) time.Time   { return o.DepartureDate }
+func ByObservation(o domain.Observation) time.Time { return o.ObservedAt }
+
+// Monthly groups observations by the month of the date picked by axis and
+// returns min, median, average and count per month, oldest first. Every
+// observation must be in the same currency.
+func Monthly(obs []domain.Observation, axis func(domain.Observation) time.Time) []domain.MonthlyPrice {
+	byMonth := make(map[string][]int64)
+	currency := ""
+	for _, o := range obs {
+		month := axis(o).UTC().Format("2006-01")
+		byMonth[month] = append(byMonth[month], o.Price.Minor)
+		currency = o.Price.Currency
+	}
+
+	out := make([]domain.MonthlyPrice, 0, len(byMonth))
+	for month, prices := range byMonth {
+		slices.Sort(prices)
+
+		var sum int64
+		for _, p := range prices {
+			sum += p
+		}
+		n := int64(len(prices))
+		median := prices[n/2]
+		if n%2 == 0 {
+			median = domain.RoundDiv(prices[n/2-1]+prices[n/2], 2)
+		}
+
+		out = append(out, domain.MonthlyPrice{
+			Month:   month,
+			Min:     domain.NewMoney(prices[0], currency),
+			Median:  domain.NewMoney(median, currency),
+			Average: domain.NewMoney(domain.RoundDiv(sum, n), currency),
+			Count:   len(prices),
+		})
+	}
+	slices.SortFunc(out, func(a, b domain.MonthlyPrice) int { return strings.Compare(a.Month, b.Month) })
+	return out
+}
@@ -0,0 +1,126 @@
+// Package history records the prices seen by searches and aggregates them
+// into price history.
+package history
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+)
+
+// Store persists price observations
+type Store interface {
+	Record(ctx context.Context, obs []domain.Observation) error
+	Query(ctx context.Context, f Filter) ([]domain.Observation, error)
+}
+
+// Filter selects observations; empty fields match everything
+type Filter struct {
+	Origins      []string
+	Destinations []string
+	Cabin        string    // prices differ too much across cabins to be compared
+	Passengers   int       // prices are for every traveler of the search
+	From, To     time.Time // observed-at range, To excluded
+	DepartFrom   time.Time // departure date range, DepartTo excluded
+	DepartTo     time.Time
+}
+
+func (f Filter) match(o domain.Observation) bool {
+	if len(f.Origins) > 0 && !slices.Contains(f.Origins, o.Origin) {
+		return false
+	}
+	if len(f.Destinations) > 0 && !slices.Contains(f.Destinations, o.Destination) {
+		return false
+	}
+	if f.Cabin != "" && o.Cabin != f.Cabin {
+		return false
+	}
+	if f.Passengers != 0 && o.Passengers != f.Passengers {
+		return false
+	}
+	if !f.DepartFrom.IsZero() && o.DepartureDate.Before(f.DepartFrom) {
+		return false
+	}
+	if !f.DepartTo.IsZero() && !o.DepartureDate.Before(f.DepartTo) {
+		return false
+	}
+	if !f.From.IsZero() && o.ObservedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !o.ObservedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// MemoryStore keeps observations in memory, indexed by route: queries naming
+// both origins and destinations only look at those routes, others scan them all
+type MemoryStore struct {
+	mu      sync.RWMutex
+	byRoute map[string][]domain.Observation
+}
+
+func routeKey(origin, destination string) string { return origin + "|" + destination }
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{byRoute: make(map[string][]domain.Observation)}
+}
+
+func (m *MemoryStore) Record(ctx context.Context, obs []domain.Observation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, o := range obs {
+		o = o.Defaults()
+		key := routeKey(o.Origin, o.Destination)
+		m.byRoute[key] = append(m.byRoute[key], o)
+	}
+	return nil
+}
+
+// Prune drops the observations seen before cutoff and returns how many
+func (m *MemoryStore) Prune(cutoff time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	dropped := 0
+	for key, obs := range m.byRoute {
+		kept := slices.DeleteFunc(obs, func(o domain.Observation) bool { return o.ObservedAt.Before(cutoff) })
+		dropped += len(obs) - len(kept)
+		if len(kept) == 0 {
+			delete(m.byRoute, key)
+		} else {
+			m.byRoute[key] = kept
+		}
+	}
+	return dropped
+}
+
+func (m *MemoryStore) Query(ctx context.Context, f Filter) ([]domain.Observation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var out []domain.Observation
+	collect := func(obs []domain.Observation) {
+		for _, o := range obs {
+			if f.match(o) {
+				out = append(out, o)
+			}
+		}
+	}
+	if len(f.Origins) > 0 && len(f.Destinations) > 0 {
+		// both ends known: only their routes can match
+		for _, origin := range f.Origins {
+			for _, dest := range f.Destinations {
+				collect(m.byRoute[routeKey(origin, dest)])
+			}
+		}
+	} else {
+		for _, obs := range m.byRoute {
+			collect(obs)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Observation) int { return a.ObservedAt.Compare(b.ObservedAt) })
+	return out, nil
+}
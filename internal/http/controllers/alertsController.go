@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/alerts"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/http/middleware"
+)
+
+type AlertsController struct {
+	service *flights.Service
+	store   alerts.Store
+}
+
+func NewAlertsController(service *flights.Service, store alerts.Store) *AlertsController {
+	return &AlertsController{service: service, store: store}
+}
+
+// Create registers an alert for the authenticated user. The response is the
+// only one that includes the webhook signing secret.
+func (a *AlertsController) Create(c *gin.Context) {
+	var req domain.AlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := a.validate(req.Search, req.Below, req.WebhookURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alert := domain.Alert{
+		ID:         alerts.NewID(),
+		User:       c.GetString(middleware.UserKey),
+		Search:     req.Search,
+		Below:      req.Below,
+		WebhookURL: req.WebhookURL,
+		Secret:     req.Secret,
+		Active:     true,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if alert.Secret == "" {
+		alert.Secret = alerts.NewID() + alerts.NewID()
+	}
+	if err := a.store.Create(c.Request.Context(), alert); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, alert)
+}
+
+func (a *AlertsController) List(c *gin.Context) {
+	list, err := a.store.List(c.Request.Context(), c.GetString(middleware.UserKey))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	c.JSON(http.StatusOK, gin.H{"alerts": list})
+}
+
+func (a *AlertsController) Get(c *gin.Context) {
+	alert, ok := a.owned(c)
+	if !ok {
+		return
+	}
+	alert.Secret = ""
+	c.JSON(http.StatusOK, alert)
+}
+
+func (a *AlertsController) Update(c *gin.Context) {
+	var req domain.AlertUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	current, ok := a.owned(c)
+	if !ok {
+		return
+	}
+
+	below, webhook := current.Below, current.WebhookURL
+	if req.Below != nil {
+		below = *req.Below
+	}
+	if req.WebhookURL != nil {
+		webhook = *req.WebhookURL
+	}
+	if err := a.validate(current.Search, below, webhook); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alert, err := a.store.Update(c.Request.Context(), current.ID, func(st *domain.Alert) error {
+		if req.Active != nil {
+			st.Active = *req.Active
+		}
+		if req.Below != nil && *req.Below != st.Below {
+			// a new threshold starts a fresh drop
+			st.Below, st.NotifiedPrice, st.NotifiedAt = *req.Below, nil, time.Time{}
+		}
+		st.WebhookURL = webhook
+		return nil
+	})
+	if errors.Is(err, alerts.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	alert.Secret = ""
+	c.JSON(http.StatusOK, alert)
+}
+
+func (a *AlertsController) Delete(c *gin.Context) {
+	alert, ok := a.owned(c)
+	if !ok {
+		return
+	}
+	if err := a.store.Delete(c.Request.Context(), alert.ID); err != nil && !errors.Is(err, alerts.ErrNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// owned loads the alert in the path, answering 404 when it doesn't exist or
+// belongs to another user
+func (a *AlertsController) owned(c *gin.Context) (domain.Alert, bool) {
+	alert, err := a.store.Get(c.Request.Context(), c.Param("id"))
+	if err == nil && alert.User != c.GetString(middleware.UserKey) {
+		err = alerts.ErrNotFound
+	}
+	if errors.Is(err, alerts.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return domain.Alert{}, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return domain.Alert{}, false
+	}
+	return alert, true
+}
+
+func (a *AlertsController) validate(search domain.SearchParams, below domain.Money, webhook string) error {
+	req, err := search.Request()
+	if err != nil {
+		return err
+	}
+	if err := a.service.Validate(req); err != nil {
+		return err
+	}
+	if below.Minor <= 0 || len(below.Currency) != 3 {
+		return errors.New("below must be a positive amount with a currency")
+	}
+	return alerts.CheckWebhookURL(webhook)
+}
@@ -2,14 +2,11 @@ package controllers
 
 import (
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/poportss/go-challenge-flight-price/internal/flights"
 	"github.com/poportss/go-challenge-flight-price/internal/http/middleware"
-	"github.com/poportss/go-challenge-flight-price/internal/providers"
-	"github.com/poportss/go-challenge-flight-price/internal/util"
 )
 
 type AuthController struct {
@@ -33,24 +30,7 @@ func (a *AuthController) Login(c *gin.Context) {
 		return
 	}
 
-	ctx := c.Request.Context()
-	amadeusToken, err := providers.GetAmadeusAccessToken(ctx)
-	if err != nil {
-		c.JSON(502, gin.H{"error": "amadeus auth failed", "details": err.Error()})
-		return
-	}
-
-	client := util.NewHTTPClient(1 * time.Minute)
-	a.service.AddProvider(providers.NewAmadeus(client, amadeusToken))
-	a.service.AddProvider(providers.NewGoogleFlights(client, os.Getenv("SERP_API_GOOGLEFLIGHTS_API_KEY")))
-	a.service.AddProvider(providers.NewMockProvider("Ports Airlines"))
-
-	providerTokens := middleware.ProviderTokens{
-		AmadeusToken:     amadeusToken,
-		GoogleFlightsKey: os.Getenv("GOOGLE_FLIGHTS_KEY"),
-	}
-
-	token, err := middleware.GenerateJWT(a.jwtSecret, body.User, time.Hour, providerTokens)
+	token, err := middleware.GenerateJWT(a.jwtSecret, body.User, time.Hour)
 	if err != nil {
 		c.JSON(500, gin.H{"error": "jwt error"})
 		return
@@ -59,6 +39,6 @@ func (a *AuthController) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"jwt_token":  token,
 		"expires_in": 3600,
-		"providers":  []string{"Google Flights", "Amadeus", "Ports Airlines"},
+		"providers":  a.service.ProviderNames(),
 	})
 }
@@ -1,8 +1,8 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
@@ -24,6 +24,105 @@ func (f *FlightsController) Search(c *gin.Context) {
 		return
 	}
 	resp, err := f.service.Search(c.Request.Context(), req)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, domain.ErrInvalidTravelers) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(502, gin.H{"error": err.Error(), "providers": resp.Providers})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (f *FlightsController) MultiCity(c *gin.Context) {
+	var req domain.MultiCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := req.Query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.SearchMultiCity(c.Request.Context(), q)
+	if errors.Is(err, flights.ErrInvalidLocation) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, flights.ErrMultiCityUnsupported) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(502, gin.H{"error": err.Error(), "providers": resp.Providers})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Calendar returns the cheapest price for every departure/return date
+// combination around the requested dates
+func (f *FlightsController) Calendar(c *gin.Context) {
+	var req domain.CalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.Calendar(c.Request.Context(), req)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, flights.ErrInvalidPeriod) || errors.Is(err, flights.ErrSearchTooLarge) ||
+		errors.Is(err, domain.ErrInvalidTravelers) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(502, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Explore ranks the cheapest dates to fly a route within a departure window
+func (f *FlightsController) Explore(c *gin.Context) {
+	var req domain.ExploreRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.Explore(c.Request.Context(), req)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, flights.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(502, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Anywhere lists the destinations reachable from an origin within a budget
+func (f *FlightsController) Anywhere(c *gin.Context) {
+	var req domain.AnywhereRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := req.Query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.Anywhere(c.Request.Context(), q)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, flights.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, flights.ErrAnywhereUnsupported) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(502, gin.H{"error": err.Error()})
 		return
@@ -31,27 +130,58 @@ func (f *FlightsController) Search(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+// Meetup ranks candidate destinations for attendees flying from several origins
+func (f *FlightsController) Meetup(c *gin.Context) {
+	var req domain.MeetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	q, err := req.Query()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.Meetup(c.Request.Context(), q)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, flights.ErrSearchTooLarge) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(502, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CacheStats reports the hit, miss and eviction counters of the response cache
+func (f *FlightsController) CacheStats(c *gin.Context) {
+	stats, ok := f.service.CacheStats()
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "the cache keeps no statistics"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
 func (f *FlightsController) History(c *gin.Context) {
-	origin := c.Query("origin")
-	dest := c.Query("destination")
-	if origin == "" || dest == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "origin and destination required"})
-		return
-	}
-
-	now := time.Now().UTC()
-	history := make([]gin.H, 0, 24)
-	for i := 0; i < 24; i++ {
-		month := now.AddDate(0, -i, 0)
-		history = append(history, gin.H{
-			"month":    month.Format("2006-01"),
-			"avgPrice": 700 + float64(i)*10 + float64(i%3)*15,
-			"currency": "USD",
-		})
-	}
-	c.JSON(http.StatusOK, gin.H{
-		"origin":      origin,
-		"destination": dest,
-		"history":     history,
-	})
+	var req domain.HistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	resp, err := f.service.History(c.Request.Context(), req)
+	if errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, flights.ErrInvalidPeriod) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, flights.ErrHistoryDisabled) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
 }
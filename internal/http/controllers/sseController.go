@@ -1,51 +1,163 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
+	"github.com/gin-contrib/sse"
 	"github.com/gin-gonic/gin"
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
 	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/watch"
+)
+
+// Poll interval bounds for the route stream, overridable with ?interval=
+const (
+	defaultStreamInterval = 30 * time.Second
+	minStreamInterval     = time.Second
+	maxStreamInterval     = 10 * time.Minute
+	heartbeatInterval     = 15 * time.Second
 )
 
 type SSEController struct {
 	service *flights.Service
+	hub     *watch.Hub
 }
 
-func NewSSEController(service *flights.Service) *SSEController {
-	return &SSEController{service: service}
+func NewSSEController(service *flights.Service, hub *watch.Hub) *SSEController {
+	return &SSEController{service: service, hub: hub}
 }
 
+// Stream subscribes to the route's shared poller and sends an "update" event,
+// identified by the offers version, only when the offers changed, followed by
+// a "diff" event with the price moves. It stops when the client disconnects.
+// Route format: ORIGIN|DESTINATION|YYYY-MM-DD[|YYYY-MM-DD]
 func (s *SSEController) Stream(c *gin.Context) {
+	req, err := parseRoute(c.Param("route"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	interval, err := streamInterval(c.Query("interval"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.service.Validate(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	sub := s.hub.Subscribe(req, interval)
+	defer sub.Close()
+
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
+	c.Status(http.StatusOK)
+
+	// a reconnecting client already has the version it names
+	st := &routeStream{w: c.Writer, version: c.GetHeader("Last-Event-ID")}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			st.heartbeat()
+		case u, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			st.publish(u.Response, u.Err)
+		}
+	}
+}
+
+// Stats reports the running route pollers and their subscriber counts
+func (s *SSEController) Stats(c *gin.Context) {
+	stats := s.hub.Stats()
+	total := 0
+	for _, st := range stats {
+		total += st.Subscribers
+	}
+	c.JSON(http.StatusOK, gin.H{"routes": stats, "subscribers": total})
+}
 
-	route := c.Param("route")
-	parts := strings.Split(route, "|")
-	if len(parts) < 3 {
-		c.SSEvent("error", "invalid route")
+// routeStream remembers the last result sent to one client
+type routeStream struct {
+	w       gin.ResponseWriter
+	version string
+	last    *domain.AggregatedResponse
+}
+
+func (st *routeStream) publish(resp domain.AggregatedResponse, err error) {
+	if err != nil {
+		st.event("", "error", gin.H{"error": err.Error(), "providers": resp.Providers})
 		return
 	}
 
-	req := domain.SearchRequest{Origin: parts[0], Destination: parts[1]}
-	req.StartDate, _ = time.Parse("2006-01-02", parts[2])
-	if len(parts) > 3 {
-		req.EndDate, _ = time.Parse("2006-01-02", parts[3])
+	version := flights.Version(resp.Offers)
+	if version == st.version {
+		return
+	}
+	st.event(version, "update", resp)
+	if st.last != nil {
+		st.event("", "diff", flights.Diff(*st.last, resp))
 	}
+	st.version = version
+	st.last = &resp
+}
 
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+func (st *routeStream) event(id, name string, data any) {
+	_ = sse.Encode(st.w, sse.Event{Id: id, Event: name, Data: data})
+	st.w.Flush()
+}
 
-	for {
-		resp, err := s.service.Search(c, req)
-		if err != nil {
-			c.SSEvent("error", err.Error())
-		} else {
-			c.SSEvent("update", resp)
+func (st *routeStream) heartbeat() {
+	_, _ = io.WriteString(st.w, ": heartbeat\n\n")
+	st.w.Flush()
+}
+
+// parseRoute parses ORIGIN|DESTINATION|YYYY-MM-DD[|YYYY-MM-DD]
+func parseRoute(route string) (domain.SearchRequest, error) {
+	parts := strings.Split(strings.TrimPrefix(route, "/"), "|")
+	if len(parts) < 3 || len(parts) > 4 {
+		return domain.SearchRequest{}, errors.New("route must be ORIGIN|DESTINATION|YYYY-MM-DD[|YYYY-MM-DD]")
+	}
+
+	req := domain.SearchRequest{Origin: parts[0], Destination: parts[1]}
+	var err error
+	if req.StartDate, err = time.Parse("2006-01-02", parts[2]); err != nil {
+		return req, fmt.Errorf("invalid departure date %q", parts[2])
+	}
+	if len(parts) == 4 {
+		if req.EndDate, err = time.Parse("2006-01-02", parts[3]); err != nil {
+			return req, fmt.Errorf("invalid return date %q", parts[3])
+		}
+		if req.EndDate.Before(req.StartDate) {
+			return req, errors.New("return date is before departure date")
 		}
-		c.Writer.Flush()
-		<-ticker.C
 	}
+	return req, nil
+}
+
+func streamInterval(raw string) (time.Duration, error) {
+	if raw == "" {
+		return defaultStreamInterval, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < minStreamInterval || d > maxStreamInterval {
+		return 0, fmt.Errorf("interval must be a duration between %s and %s", minStreamInterval, maxStreamInterval)
+	}
+	return d, nil
 }
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+)
+
+// Stream formats supported by SearchStream
+const (
+	formatSSE    = "sse"
+	formatNDJSON = "ndjson"
+)
+
+type StreamController struct {
+	service *flights.Service
+}
+
+func NewStreamController(service *flights.Service) *StreamController {
+	return &StreamController{service: service}
+}
+
+// Search streams each provider's quotes as they arrive ("provider" events),
+// then the aggregated response ("summary") or an "error" event.
+// The format is picked with ?format=sse|ndjson or the Accept header.
+func (s *StreamController) Search(c *gin.Context) {
+	var req domain.SearchRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = formatSSE
+		if strings.Contains(c.GetHeader("Accept"), "application/x-ndjson") {
+			format = formatNDJSON
+		}
+	}
+	if format != formatSSE && format != formatNDJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be sse or ndjson"})
+		return
+	}
+	w := &eventWriter{c: c, format: format}
+
+	resp, err := s.service.SearchStream(c.Request.Context(), req, func(r domain.ProviderResult) {
+		w.event("provider", r)
+	})
+	// nothing was streamed yet, so validation errors can still be plain 400s
+	if (errors.Is(err, flights.ErrInvalidLocation) || errors.Is(err, domain.ErrInvalidTravelers)) && !w.started {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		w.event("error", gin.H{"error": err.Error(), "providers": resp.Providers})
+		return
+	}
+	w.event("summary", resp)
+}
+
+// eventWriter writes named events as SSE or NDJSON, sending the headers with
+// the first event
+type eventWriter struct {
+	c       *gin.Context
+	format  string
+	started bool
+}
+
+func (w *eventWriter) event(name string, data any) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Cache-Control", "no-cache")
+		w.c.Header("Connection", "keep-alive")
+		if w.format == formatNDJSON {
+			w.c.Header("Content-Type", "application/x-ndjson")
+		} else {
+			w.c.Header("Content-Type", "text/event-stream")
+		}
+		w.c.Status(http.StatusOK)
+	}
+
+	if w.format == formatNDJSON {
+		_ = json.NewEncoder(w.c.Writer).Encode(gin.H{"event": name, "data": data})
+	} else {
+		w.c.SSEvent(name, data)
+	}
+	w.c.Writer.Flush()
+}
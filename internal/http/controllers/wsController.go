@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gorilla/websocket"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/watch"
+)
+
+const (
+	maxWatchSubscriptions = 50
+	wsWriteTimeout        = 10 * time.Second
+	wsPongTimeout         = 60 * time.Second
+	wsPingInterval        = wsPongTimeout * 9 / 10
+	wsMaxMessageBytes     = 64 << 10
+)
+
+// Message types exchanged on the watch WebSocket
+const (
+	wsSubscribe    = "subscribe"
+	wsUnsubscribe  = "unsubscribe"
+	wsSubscribed   = "subscribed"
+	wsUnsubscribed = "unsubscribed"
+	wsUpdate       = "update"
+	wsDiff         = "diff"
+	wsProviders    = "providers"
+	wsError        = "error"
+)
+
+// wsCommand is a message sent by the client
+type wsCommand struct {
+	Type     string               `json:"type"`
+	ID       string               `json:"id"`
+	Search   *domain.SearchParams `json:"search,omitempty"`
+	Interval string               `json:"interval,omitempty"`
+}
+
+// wsMessage is a message sent to the client; ID names the subscription it is about
+type wsMessage struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	Route   string `json:"route,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Data    any    `json:"data,omitempty"`
+}
+
+type WSController struct {
+	service  *flights.Service
+	hub      *watch.Hub
+	upgrader websocket.Upgrader
+}
+
+// NewWSController accepts handshakes from the server's own origin, from
+// clients that send no Origin (not browsers) and from allowedOrigins
+func NewWSController(service *flights.Service, hub *watch.Hub, allowedOrigins []string) *WSController {
+	return &WSController{
+		service:  service,
+		hub:      hub,
+		upgrader: websocket.Upgrader{CheckOrigin: checkOrigin(allowedOrigins)},
+	}
+}
+
+// checkOrigin keeps pages of other sites from using a token they got hold of
+// in the user's browser
+func checkOrigin(allowed []string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, a := range allowed {
+			if a == "*" || strings.EqualFold(strings.TrimSuffix(a, "/"), origin) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Watch upgrades to a WebSocket on which the client subscribes to and
+// unsubscribes from any number of searches, each polled by the shared hub
+func (w *WSController) Watch(c *gin.Context) {
+	conn, err := w.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // the upgrader already replied with an HTTP error
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s := &watchSession{ctl: w, conn: conn, ctx: ctx, out: make(chan wsMessage, 32), subs: make(map[string]*watch.Subscription)}
+
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		s.write(cancel)
+	}()
+
+	s.read()
+
+	cancel()
+	for _, sub := range s.subs {
+		sub.Close()
+	}
+	s.forwarders.Wait()
+	writer.Wait()
+}
+
+// watchSession is one WebSocket connection and its subscriptions
+type watchSession struct {
+	ctl  *WSController
+	conn *websocket.Conn
+	ctx  context.Context
+	out  chan wsMessage
+
+	subs       map[string]*watch.Subscription // only touched by the read loop
+	forwarders sync.WaitGroup
+}
+
+// read handles client commands until the connection fails or closes
+func (s *watchSession) read() {
+	s.conn.SetReadLimit(wsMaxMessageBytes)
+	_ = s.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		var cmd wsCommand
+		if err := s.conn.ReadJSON(&cmd); err != nil {
+			// JSON errors leave the connection usable, anything else ends it
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+				s.send(wsMessage{Type: wsError, Error: "malformed message"})
+				continue
+			}
+			return
+		}
+
+		switch cmd.Type {
+		case wsSubscribe:
+			s.subscribe(cmd)
+		case wsUnsubscribe:
+			s.unsubscribe(cmd.ID)
+		default:
+			s.send(wsMessage{Type: wsError, ID: cmd.ID, Error: fmt.Sprintf("unknown message type %q", cmd.Type)})
+		}
+	}
+}
+
+func (s *watchSession) subscribe(cmd wsCommand) {
+	fail := func(msg string) { s.send(wsMessage{Type: wsError, ID: cmd.ID, Error: msg}) }
+
+	switch {
+	case cmd.ID == "":
+		fail("subscription id is required")
+		return
+	case s.subs[cmd.ID] != nil:
+		fail("already subscribed")
+		return
+	case len(s.subs) >= maxWatchSubscriptions:
+		fail(fmt.Sprintf("at most %d subscriptions per connection", maxWatchSubscriptions))
+		return
+	case cmd.Search == nil:
+		fail("search is required")
+		return
+	}
+	if err := binding.Validator.ValidateStruct(cmd.Search); err != nil {
+		fail(err.Error())
+		return
+	}
+	req, err := cmd.Search.Request()
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+	if err := s.ctl.service.Validate(req); err != nil {
+		fail(err.Error())
+		return
+	}
+	interval, err := streamInterval(cmd.Interval)
+	if err != nil {
+		fail(err.Error())
+		return
+	}
+
+	sub := s.ctl.hub.Subscribe(req, interval)
+	s.subs[cmd.ID] = sub
+	s.send(wsMessage{Type: wsSubscribed, ID: cmd.ID, Route: watch.Route(req)})
+
+	s.forwarders.Add(1)
+	go func() {
+		defer s.forwarders.Done()
+		s.forward(cmd.ID, sub)
+	}()
+}
+
+func (s *watchSession) unsubscribe(id string) {
+	sub, ok := s.subs[id]
+	if !ok {
+		s.send(wsMessage{Type: wsError, ID: id, Error: "not subscribed"})
+		return
+	}
+	delete(s.subs, id)
+	sub.Close()
+	s.send(wsMessage{Type: wsUnsubscribed, ID: id})
+}
+
+// forward turns the hub updates of one subscription into messages until it is closed
+func (s *watchSession) forward(id string, sub *watch.Subscription) {
+	var last *domain.AggregatedResponse
+	var lastProviders string
+
+	for u := range sub.C {
+		if providers := providersSignature(u.Response); providers != lastProviders {
+			lastProviders = providers
+			s.send(wsMessage{Type: wsProviders, ID: id, Data: gin.H{"providers": u.Response.Providers, "partial": u.Response.Partial}})
+		}
+		if u.Err != nil {
+			s.send(wsMessage{Type: wsError, ID: id, Error: u.Err.Error()})
+			continue
+		}
+
+		s.send(wsMessage{Type: wsUpdate, ID: id, Version: u.Version, Data: u.Response})
+		if last != nil {
+			s.send(wsMessage{Type: wsDiff, ID: id, Version: u.Version, Data: flights.Diff(*last, u.Response)})
+		}
+		resp := u.Response
+		last = &resp
+	}
+}
+
+func (s *watchSession) send(m wsMessage) {
+	select {
+	case s.out <- m:
+	case <-s.ctx.Done():
+	}
+}
+
+// write sends queued messages and keep-alive pings, cancelling the session on failure
+func (s *watchSession) write(cancel context.CancelFunc) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			_ = s.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(wsWriteTimeout))
+			return
+		case m := <-s.out:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := s.conn.WriteJSON(m); err != nil {
+				log.Printf("✗ WebSocket write failed: %v", err)
+				cancel()
+				_ = s.conn.Close() // unblocks the read loop
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				cancel()
+				_ = s.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// providersSignature summarizes provider outcomes to detect changes
+func providersSignature(resp domain.AggregatedResponse) string {
+	sig := ""
+	for _, p := range resp.Providers {
+		sig += p.Name + "=" + p.Status + ";"
+	}
+	return sig
+}
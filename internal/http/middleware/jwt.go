@@ -9,38 +9,45 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-type ProviderTokens struct {
-	AmadeusToken     string `json:"amadeus_token"`
-	GoogleFlightsKey string `json:"google_flights_key"`
-}
+// UserKey is the gin context key holding the authenticated user
+const UserKey = "user"
 
 type CustomClaims struct {
-	User      string         `json:"user"`
-	Providers ProviderTokens `json:"providers"`
+	User string `json:"user"`
 	jwt.RegisteredClaims
 }
 
 func JWT(secret string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		h := c.GetHeader("Authorization")
+		// browsers can't set headers on WebSocket handshakes, so those may pass the token in the query
+		if h == "" && c.IsWebsocket() && c.Query("access_token") != "" {
+			h = "Bearer " + c.Query("access_token")
+		}
 		if !strings.HasPrefix(h, "Bearer ") {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "missing bearer"})
 			return
 		}
 		tok := strings.TrimPrefix(h, "Bearer ")
-		_, err := jwt.Parse(tok, func(t *jwt.Token) (any, error) { return []byte(secret), nil })
+		claims := &CustomClaims{}
+		_, err := jwt.ParseWithClaims(tok, claims, func(t *jwt.Token) (any, error) { return []byte(secret), nil })
 		if err != nil {
 			c.AbortWithStatusJSON(401, gin.H{"error": "invalid token"})
 			return
 		}
+		// resources such as alerts are owned by the user, so a token without one is useless
+		if strings.TrimSpace(claims.User) == "" {
+			c.AbortWithStatusJSON(401, gin.H{"error": "token has no user"})
+			return
+		}
+		c.Set(UserKey, claims.User)
 		c.Next()
 	}
 }
 
-func GenerateJWT(secret, user string, ttl time.Duration, tokens ProviderTokens) (string, error) {
+func GenerateJWT(secret, user string, ttl time.Duration) (string, error) {
 	claims := CustomClaims{
-		User:      user,
-		Providers: tokens,
+		User: user,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 		},
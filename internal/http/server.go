@@ -2,24 +2,49 @@ package httpserver
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/alerts"
 	"github.com/poportss/go-challenge-flight-price/internal/flights"
 	"github.com/poportss/go-challenge-flight-price/internal/http/controllers"
 	"github.com/poportss/go-challenge-flight-price/internal/http/middleware"
+	"github.com/poportss/go-challenge-flight-price/internal/watch"
 )
 
 type Server struct {
 	engine  *gin.Engine
 	service *flights.Service
+	alerts  alerts.Store
+	origins []string // extra origins allowed to open WebSockets
 }
 
-func New(service *flights.Service, jwtSecret string) *Server {
-	r := gin.Default()
+// Option enables optional features of the server
+type Option func(*Server)
+
+// WithAlerts serves the price alert endpoints backed by store
+func WithAlerts(store alerts.Store) Option {
+	return func(s *Server) { s.alerts = store }
+}
+
+// WithAllowedOrigins lets browser pages served from origins, such as
+// "https://app.example.com", open WebSockets besides the server's own origin
+func WithAllowedOrigins(origins ...string) Option {
+	return func(s *Server) { s.origins = origins }
+}
+
+func New(service *flights.Service, jwtSecret string, opts ...Option) *Server {
+	r := gin.New()
+	r.Use(middleware.Logger(), gin.Recovery())
 	srv := &Server{engine: r, service: service}
+	for _, opt := range opts {
+		opt(srv)
+	}
 
 	// Controllers
 	authCtrl := controllers.NewAuthController(service, jwtSecret)
 	flightsCtrl := controllers.NewFlightsController(service)
-	sseCtrl := controllers.NewSSEController(service)
+	hub := watch.NewHub(service)
+	sseCtrl := controllers.NewSSEController(service, hub)
+	wsCtrl := controllers.NewWSController(service, hub, srv.origins)
+	streamCtrl := controllers.NewStreamController(service)
 
 	// public routes
 	r.POST("/login", authCtrl.Login)
@@ -27,8 +52,26 @@ func New(service *flights.Service, jwtSecret string) *Server {
 	// private routes
 	auth := r.Group("/", middleware.JWT(jwtSecret))
 	auth.GET("/flights/search", flightsCtrl.Search)
+	auth.GET("/flights/search/stream", streamCtrl.Search)
+	auth.POST("/flights/multi-city", flightsCtrl.MultiCity)
+	auth.GET("/flights/calendar", flightsCtrl.Calendar)
+	auth.GET("/flights/explore", flightsCtrl.Explore)
+	auth.GET("/flights/anywhere", flightsCtrl.Anywhere)
+	auth.POST("/flights/meetup", flightsCtrl.Meetup)
 	auth.GET("/flights/history", flightsCtrl.History)
+	auth.GET("/cache/stats", flightsCtrl.CacheStats)
+	auth.GET("/sse/stats", sseCtrl.Stats)
 	auth.GET("/sse/:route", sseCtrl.Stream)
+	auth.GET("/ws/watch", wsCtrl.Watch)
+
+	if srv.alerts != nil {
+		alertsCtrl := controllers.NewAlertsController(service, srv.alerts)
+		auth.POST("/alerts", alertsCtrl.Create)
+		auth.GET("/alerts", alertsCtrl.List)
+		auth.GET("/alerts/:id", alertsCtrl.Get)
+		auth.PATCH("/alerts/:id", alertsCtrl.Update)
+		auth.DELETE("/alerts/:id", alertsCtrl.Delete)
+	}
 
 	return srv
 }
@@ -1,134 +1,628 @@
 package providers
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
-	"github.com/poportss/go-challenge-flight-price/internal/util"
+	"golang.org/x/sync/singleflight"
 )
 
+// tokenRefreshMargin is how long before expiry the access token is renewed;
+// tokens living less than twice as long are renewed halfway through instead
+const tokenRefreshMargin = 2 * time.Minute
+
+// errUnauthorized signals that Amadeus rejected the current access token
+var errUnauthorized = errors.New("amadeus: unauthorized")
+
 // Amadeus provider
 type Amadeus struct {
 	client       *http.Client
 	baseURL      string
 	clientID     string
 	clientSecret string
-	token        string
-	tokenExp     time.Time
+
+	mu      sync.Mutex
+	token   string
+	renewAt time.Time // when the token is due for renewal, ahead of its expiry
+	refresh singleflight.Group
 }
 
-func NewAmadeus(client *http.Client, token string) *Amadeus {
+// DefaultAmadeusBaseURL is the Amadeus self-service test environment
+const DefaultAmadeusBaseURL = "https://test.api.amadeus.com"
+
+func NewAmadeus(client *http.Client, baseURL, clientID, clientSecret string) *Amadeus {
+	if baseURL == "" {
+		baseURL = DefaultAmadeusBaseURL
+	}
 	return &Amadeus{
-		client:  client,
-		baseURL: util.EnvOr("AMADEUS_BASE_URL", "https://test.api.amadeus.com"),
-		token:   token,
+		client:       client,
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
 	}
 }
 
 func (a *Amadeus) Name() string { return "Amadeus" }
 
 // Search implements the Provider interface and fetches flight data from amadeus
-func (a *Amadeus) Search(ctx context.Context, origin, destination string, startDate, endDate time.Time) ([]domain.Quote, error) {
-	url := fmt.Sprintf("%s/v2/shopping/flight-offers?originLocationCode=%s&destinationLocationCode=%s&departureDate=%s&returnDate=%s&adults=1&max=3",
-		a.baseURL, origin, destination, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+func (a *Amadeus) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	endpoint := a.baseURL + "/v2/shopping/flight-offers?" + amadeusParams(q).Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	out, err := a.offers(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
-		return nil, fmt.Errorf("amadeus: build request failed: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Bearer "+a.token)
+	quotes := make([]domain.Quote, 0, len(out.Data))
+	var invalid error // last offer rejected for a malformed price
+	for _, d := range out.Data {
+		if len(d.Itineraries) == 0 {
+			continue
+		}
 
-	resp, err := a.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("amadeus: http request failed: %w", err)
+		outbound, err := amadeusItinerary(d.Itineraries[0])
+		if err != nil {
+			continue
+		}
+
+		var inbound *domain.Itinerary
+		if len(d.Itineraries) > 1 {
+			in, err := amadeusItinerary(d.Itineraries[1])
+			if err != nil {
+				continue
+			}
+			inbound = &in
+		}
+
+		price, err := domain.ParseMoney(d.Price.GrandTotal, d.Price.Currency)
+		if err != nil {
+			invalid = fmt.Errorf("amadeus: offer %s: %w", d.Id, err)
+			continue
+		}
+
+		quotes = append(quotes, domain.Quote{
+			Provider:       a.Name(),
+			Airline:        outbound.Segments[0].Carrier,
+			Price:          price,
+			Duration:       outbound.Duration,
+			DepartureAt:    outbound.DepartureAt,
+			ArrivalAt:      outbound.ArrivalAt,
+			DepartureAtUTC: outbound.DepartureAt.UTC(),
+			ArrivalAtUTC:   outbound.ArrivalAt.UTC(),
+			Origin:         outbound.Origin,
+			Destination:    outbound.Destination,
+			Outbound:       &outbound,
+			Inbound:        inbound,
+		})
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("amadeus: unexpected status %d: %s", resp.StatusCode, string(body))
+	// no offers is a valid answer; offers that all failed to parse are not
+	if len(quotes) == 0 && invalid != nil {
+		return nil, invalid
+	}
+	if len(quotes) == 0 && len(out.Data) > 0 {
+		return nil, fmt.Errorf("amadeus: no valid quotes found for %s→%s", q.Origin, q.Destination)
 	}
 
-	var out domain.AmadeusResponse
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("amadeus: json decode failed: %w | raw: %s", err, string(body))
+	return quotes, nil
+}
+
+// amadeusParams translates the query into flight-offers GET parameters
+func amadeusParams(q domain.SearchQuery) url.Values {
+	v := url.Values{}
+	v.Set("originLocationCode", q.Origin)
+	v.Set("destinationLocationCode", q.Destination)
+	v.Set("departureDate", q.DepartureDate.Format("2006-01-02"))
+	if !q.OneWay() {
+		v.Set("returnDate", q.ReturnDate.Format("2006-01-02"))
+	}
+	v.Set("adults", strconv.Itoa(max(q.Adults, 1)))
+	if q.Children > 0 {
+		v.Set("children", strconv.Itoa(q.Children))
+	}
+	if q.Infants > 0 {
+		v.Set("infants", strconv.Itoa(q.Infants))
+	}
+	if q.Cabin != "" {
+		v.Set("travelClass", q.Cabin)
+	}
+	if q.NonStop {
+		v.Set("nonStop", "true")
+	}
+	if q.Currency != "" {
+		v.Set("currencyCode", q.Currency)
+	}
+	if q.MaxResults > 0 {
+		v.Set("max", strconv.Itoa(q.MaxResults))
+	}
+	return v
+}
+
+// SearchMultiCity implements the MultiCityProvider interface using the
+// flight-offers POST endpoint, which takes one originDestination per leg
+func (a *Amadeus) SearchMultiCity(ctx context.Context, q domain.MultiCityQuery) ([]domain.Quote, error) {
+	body, err := json.Marshal(amadeusMultiCityBody(q))
+	if err != nil {
+		return nil, fmt.Errorf("amadeus: encode request failed: %w", err)
+	}
+
+	out, err := a.offers(ctx, http.MethodPost, a.baseURL+"/v2/shopping/flight-offers", body)
+	if err != nil {
+		return nil, err
 	}
 
 	quotes := make([]domain.Quote, 0, len(out.Data))
+	var invalid error // last offer rejected for a malformed price
 	for _, d := range out.Data {
-		if len(d.Itineraries) == 0 || len(d.Itineraries[0].Segments) == 0 {
+		if len(d.Itineraries) != len(q.Legs) {
 			continue
 		}
 
-		segments := d.Itineraries[0].Segments
-		first := segments[0]
-		last := segments[len(segments)-1]
-
-		layout := "2006-01-02T15:04:05"
-		dep, err1 := time.Parse(layout, first.Departure.At)
-		arr, err2 := time.Parse(layout, last.Arrival.At)
-		if err1 != nil || err2 != nil {
+		itineraries := make([]domain.Itinerary, 0, len(d.Itineraries))
+		var total time.Duration
+		for _, it := range d.Itineraries {
+			itinerary, err := amadeusItinerary(it)
+			if err != nil {
+				break
+			}
+			itineraries = append(itineraries, itinerary)
+			total += itinerary.Duration
+		}
+		if len(itineraries) != len(q.Legs) {
 			continue
 		}
 
-		price, _ := strconv.ParseFloat(d.Price.GrandTotal, 64)
+		price, err := domain.ParseMoney(d.Price.GrandTotal, d.Price.Currency)
+		if err != nil {
+			invalid = fmt.Errorf("amadeus: offer %s: %w", d.Id, err)
+			continue
+		}
 
 		quotes = append(quotes, domain.Quote{
-			Provider:    a.Name(),
-			Airline:     first.CarrierCode,
-			Price:       price,
-			Currency:    d.Price.Currency,
-			Duration:    arr.Sub(dep),
-			DepartureAt: dep,
-			ArrivalAt:   arr,
-			Origin:      origin,
-			Destination: destination,
+			Provider:       a.Name(),
+			Airline:        itineraries[0].Segments[0].Carrier,
+			Price:          price,
+			Duration:       total,
+			DepartureAt:    itineraries[0].DepartureAt,
+			ArrivalAt:      itineraries[len(itineraries)-1].ArrivalAt,
+			DepartureAtUTC: itineraries[0].DepartureAt.UTC(),
+			ArrivalAtUTC:   itineraries[len(itineraries)-1].ArrivalAt.UTC(),
+			Origin:         q.Legs[0].Origin,
+			Destination:    q.Legs[len(q.Legs)-1].Destination,
+			Itineraries:    itineraries,
 		})
 	}
 
-	if len(quotes) == 0 {
-		return nil, fmt.Errorf("amadeus: no valid quotes found for %s→%s", origin, destination)
+	if len(quotes) == 0 && invalid != nil {
+		return nil, invalid
+	}
+	if len(quotes) == 0 && len(out.Data) > 0 {
+		return nil, fmt.Errorf("amadeus: no valid multi-city quotes found")
 	}
 
 	return quotes, nil
 }
 
-func GetAmadeusAccessToken(ctx context.Context) (string, error) {
-	client := util.NewHTTPClient(10 * time.Second)
+// amadeusItinerary converts an Amadeus itinerary and its segments
+func amadeusItinerary(it domain.AmadeusItinerary) (domain.Itinerary, error) {
+	if len(it.Segments) == 0 {
+		return domain.Itinerary{}, errors.New("amadeus: itinerary without segments")
+	}
+
+	layout := "2006-01-02T15:04:05"
+	segments := make([]domain.Segment, 0, len(it.Segments))
+	for _, seg := range it.Segments {
+		duration, durErr := parseISODuration(seg.Duration)
+
+		// Amadeus returns local airport times without an offset
+		dep, arr, err := segmentTimes(layout, seg.Departure.At, seg.Departure.IataCode, seg.Arrival.At, seg.Arrival.IataCode, duration)
+		if err != nil {
+			return domain.Itinerary{}, fmt.Errorf("amadeus: invalid segment times %q → %q: %w", seg.Departure.At, seg.Arrival.At, err)
+		}
+		if durErr != nil {
+			duration = arr.Sub(dep)
+		}
+
+		operating := seg.Operating.CarrierCode
+		if operating == seg.CarrierCode {
+			operating = ""
+		}
+
+		segments = append(segments, domain.Segment{
+			Carrier:           seg.CarrierCode,
+			FlightNumber:      seg.CarrierCode + seg.Number,
+			OperatingCarrier:  operating,
+			Aircraft:          seg.Aircraft.Code,
+			Origin:            seg.Departure.IataCode,
+			DepartureTerminal: seg.Departure.Terminal,
+			DepartureAt:       dep,
+			Destination:       seg.Arrival.IataCode,
+			ArrivalTerminal:   seg.Arrival.Terminal,
+			ArrivalAt:         arr,
+			Duration:          duration,
+			Stops:             seg.NumberOfStops,
+		})
+	}
+
+	itinerary := domain.NewItinerary(segments)
+	if d, err := parseISODuration(it.Duration); err == nil {
+		itinerary.Duration = d
+	}
+	return itinerary, nil
+}
+
+// parseISODuration parses the ISO-8601 durations used by Amadeus, e.g. "PT7H30M" or "P1DT2H"
+func parseISODuration(s string) (time.Duration, error) {
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+	}
+
+	var total time.Duration
+	inTime := false
+	num := 0
+	digits := false
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			num = num*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		}
+
+		if !digits {
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+		}
+		n := time.Duration(num)
+		switch {
+		case r == 'D' && !inTime:
+			total += n * 24 * time.Hour
+		case r == 'H' && inTime:
+			total += n * time.Hour
+		case r == 'M' && inTime:
+			total += n * time.Minute
+		case r == 'S' && inTime:
+			total += n * time.Second
+		default:
+			return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+		}
+		num, digits = 0, false
+	}
+	if digits {
+		return 0, fmt.Errorf("invalid ISO-8601 duration %q", s)
+	}
+	return total, nil
+}
+
+type amadeusOriginDestination struct {
+	ID                      string `json:"id"`
+	OriginLocationCode      string `json:"originLocationCode"`
+	DestinationLocationCode string `json:"destinationLocationCode"`
+	DepartureDateTimeRange  struct {
+		Date string `json:"date"`
+	} `json:"departureDateTimeRange"`
+}
+
+type amadeusTraveler struct {
+	ID                string `json:"id"`
+	TravelerType      string `json:"travelerType"`
+	AssociatedAdultID string `json:"associatedAdultId,omitempty"`
+}
+
+type amadeusCabinRestriction struct {
+	Cabin                string   `json:"cabin"`
+	Coverage             string   `json:"coverage"`
+	OriginDestinationIDs []string `json:"originDestinationIds"`
+}
+
+type amadeusConnectionRestriction struct {
+	MaxNumberOfConnections int `json:"maxNumberOfConnections"`
+}
+
+type amadeusSearchBody struct {
+	CurrencyCode       string                     `json:"currencyCode,omitempty"`
+	OriginDestinations []amadeusOriginDestination `json:"originDestinations"`
+	Travelers          []amadeusTraveler          `json:"travelers"`
+	Sources            []string                   `json:"sources"`
+	SearchCriteria     struct {
+		MaxFlightOffers int `json:"maxFlightOffers,omitempty"`
+		FlightFilters   struct {
+			CabinRestrictions     []amadeusCabinRestriction     `json:"cabinRestrictions,omitempty"`
+			ConnectionRestriction *amadeusConnectionRestriction `json:"connectionRestriction,omitempty"`
+		} `json:"flightFilters"`
+	} `json:"searchCriteria"`
+}
+
+// amadeusMultiCityBody translates the query into a flight-offers POST body
+func amadeusMultiCityBody(q domain.MultiCityQuery) amadeusSearchBody {
+	body := amadeusSearchBody{
+		CurrencyCode: q.Currency,
+		Sources:      []string{"GDS"},
+	}
+
+	ids := make([]string, 0, len(q.Legs))
+	for i, l := range q.Legs {
+		od := amadeusOriginDestination{
+			ID:                      strconv.Itoa(i + 1),
+			OriginLocationCode:      l.Origin,
+			DestinationLocationCode: l.Destination,
+		}
+		od.DepartureDateTimeRange.Date = l.Date.Format("2006-01-02")
+		body.OriginDestinations = append(body.OriginDestinations, od)
+		ids = append(ids, od.ID)
+	}
+
+	id := 0
+	addTravelers := func(n int, kind string, adult func(i int) string) {
+		for i := 0; i < n; i++ {
+			id++
+			body.Travelers = append(body.Travelers, amadeusTraveler{
+				ID:                strconv.Itoa(id),
+				TravelerType:      kind,
+				AssociatedAdultID: adult(i),
+			})
+		}
+	}
+	none := func(int) string { return "" }
+	addTravelers(max(q.Adults, 1), "ADULT", none)
+	addTravelers(q.Children, "CHILD", none)
+	// each infant travels on the lap of one of the adults, who hold the first ids;
+	// SearchOptions.Validate guarantees there are at least as many adults
+	addTravelers(q.Infants, "HELD_INFANT", func(i int) string { return strconv.Itoa(i + 1) })
+
+	body.SearchCriteria.MaxFlightOffers = q.MaxResults
+	if q.Cabin != "" {
+		body.SearchCriteria.FlightFilters.CabinRestrictions = []amadeusCabinRestriction{{
+			Cabin:                q.Cabin,
+			Coverage:             "MOST_SEGMENTS",
+			OriginDestinationIDs: ids,
+		}}
+	}
+	if q.NonStop {
+		body.SearchCriteria.FlightFilters.ConnectionRestriction = &amadeusConnectionRestriction{MaxNumberOfConnections: 0}
+	}
+	return body
+}
+
+// CheapestDates implements CheapestDateProvider with the Flight Cheapest Date Search
+func (a *Amadeus) CheapestDates(ctx context.Context, q domain.CheapestDateQuery) ([]domain.DatePrice, error) {
+	endpoint := a.baseURL + "/v1/shopping/flight-dates?" + amadeusDatesParams(q).Encode()
+
+	var out domain.AmadeusFlightDatesResponse
+	if err := a.do(ctx, http.MethodGet, endpoint, nil, &out); err != nil {
+		return nil, err
+	}
+
+	prices := make([]domain.DatePrice, 0, len(out.Data))
+	var invalid error
+	for _, d := range out.Data {
+		price, err := domain.ParseMoney(d.Price.Total, out.Meta.Currency)
+		if err != nil {
+			invalid = fmt.Errorf("amadeus: flight date %s: %w", d.DepartureDate, err)
+			continue
+		}
+		prices = append(prices, domain.DatePrice{
+			DepartureDate: d.DepartureDate,
+			ReturnDate:    d.ReturnDate,
+			Price:         price,
+			Provider:      a.Name(),
+			Source:        domain.SourceLive,
+		})
+	}
+
+	if len(prices) == 0 && invalid != nil {
+		return nil, invalid
+	}
+	return prices, nil
+}
 
+// amadeusDatesParams translates the query into flight-dates GET parameters
+func amadeusDatesParams(q domain.CheapestDateQuery) url.Values {
+	params := url.Values{}
+	params.Set("origin", q.Origin)
+	params.Set("destination", q.Destination)
+	params.Set("departureDate", q.From.Format("2006-01-02")+","+q.To.Format("2006-01-02"))
+	params.Set("oneWay", strconv.FormatBool(q.OneWay))
+	if !q.OneWay {
+		params.Set("duration", fmt.Sprintf("%d,%d", q.MinDays, q.MaxDays))
+	}
+	params.Set("nonStop", strconv.FormatBool(q.NonStop))
+	params.Set("viewBy", "DATE")
+	return params
+}
+
+// Destinations implements DestinationProvider with the Flight Inspiration Search.
+// Its maxPrice is in the account currency, which is only known from the
+// response, so the budget is left to the caller.
+func (a *Amadeus) Destinations(ctx context.Context, q domain.AnywhereQuery) ([]domain.DestinationPrice, error) {
+	params := url.Values{}
+	params.Set("origin", q.Origin)
+	params.Set("departureDate", q.From.Format("2006-01-02")+","+q.To.Format("2006-01-02"))
+	params.Set("oneWay", strconv.FormatBool(q.OneWay))
+	if !q.OneWay {
+		params.Set("duration", fmt.Sprintf("%d,%d", q.MinDays, q.MaxDays))
+	}
+	params.Set("nonStop", strconv.FormatBool(q.NonStop))
+	params.Set("viewBy", "DESTINATION")
+	endpoint := a.baseURL + "/v1/shopping/flight-destinations?" + params.Encode()
+
+	var out domain.AmadeusFlightDestinationsResponse
+	if err := a.do(ctx, http.MethodGet, endpoint, nil, &out); err != nil {
+		return nil, err
+	}
+
+	dests := make([]domain.DestinationPrice, 0, len(out.Data))
+	var invalid error
+	for _, d := range out.Data {
+		price, err := domain.ParseMoney(d.Price.Total, out.Meta.Currency)
+		if err != nil {
+			invalid = fmt.Errorf("amadeus: destination %s: %w", d.Destination, err)
+			continue
+		}
+		dests = append(dests, domain.DestinationPrice{
+			Destination:   d.Destination,
+			DepartureDate: d.DepartureDate,
+			ReturnDate:    d.ReturnDate,
+			Price:         price,
+			Provider:      a.Name(),
+		})
+	}
+
+	if len(dests) == 0 && invalid != nil {
+		return nil, invalid
+	}
+	return dests, nil
+}
+
+// offers performs a flight-offers request
+func (a *Amadeus) offers(ctx context.Context, method, endpoint string, body []byte) (domain.AmadeusResponse, error) {
+	var out domain.AmadeusResponse
+	err := a.do(ctx, method, endpoint, body, &out)
+	return out, err
+}
+
+// do performs an authenticated request and decodes the JSON response into
+// out, renewing the token and retrying once on 401
+func (a *Amadeus) do(ctx context.Context, method, endpoint string, body []byte, out any) error {
+	token, err := a.AccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	err = a.fetch(ctx, token, method, endpoint, body, out)
+	if errors.Is(err, errUnauthorized) {
+		// the token may have been revoked before its expiry: renew it and retry once
+		a.invalidateToken(token)
+		if token, err = a.AccessToken(ctx); err != nil {
+			return err
+		}
+		err = a.fetch(ctx, token, method, endpoint, body, out)
+	}
+	return err
+}
+
+// fetch performs a request authenticated with the given token
+func (a *Amadeus) fetch(ctx context.Context, token, method, endpoint string, body []byte, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("amadeus: build request failed: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		// Amadeus requires the method override header for POST searches
+		req.Header.Set("X-HTTP-Method-Override", http.MethodGet)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("amadeus: http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errUnauthorized
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Provider: "amadeus", Code: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("amadeus: json decode failed: %w | raw: %s", err, string(body))
+	}
+
+	return nil
+}
+
+// AccessToken returns a valid OAuth access token, requesting a new one when
+// the cached token is missing or about to expire. Concurrent callers share a
+// single in-flight refresh.
+func (a *Amadeus) AccessToken(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	token, renewAt := a.token, a.renewAt
+	a.mu.Unlock()
+
+	if token != "" && time.Now().Before(renewAt) {
+		return token, nil
+	}
+
+	ch := a.refresh.DoChan("token", func() (any, error) {
+		// the refresh outlives any single caller so a cancelled request
+		// doesn't fail the others waiting on it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		return a.requestToken(ctx)
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
+// invalidateToken drops the cached token so the next call fetches a new one,
+// unless it has already been replaced: concurrent requests rejected with the
+// same token must not discard the one renewed by the first of them
+func (a *Amadeus) invalidateToken(token string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.token == token {
+		a.token = ""
+		a.renewAt = time.Time{}
+	}
+}
+
+// requestToken fetches a new token with the client credentials grant and caches it
+func (a *Amadeus) requestToken(ctx context.Context) (string, error) {
 	form := url.Values{}
 	form.Set("grant_type", "client_credentials")
-	form.Set("client_id", os.Getenv("AMADEUS_CLIENT_ID"))
-	form.Set("client_secret", os.Getenv("AMADEUS_CLIENT_SECRET"))
+	form.Set("client_id", a.clientID)
+	form.Set("client_secret", a.clientSecret)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
-		"https://test.api.amadeus.com/v1/security/oauth2/token",
+		a.baseURL+"/v1/security/oauth2/token",
 		strings.NewReader(form.Encode()))
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("amadeus: build auth request failed: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := client.Do(req)
+	resp, err := a.client.Do(req)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("amadeus: auth request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("amadeus auth failed: %s", string(body))
+		return "", fmt.Errorf("amadeus auth failed: %w", &StatusError{Provider: "amadeus", Code: resp.StatusCode, Body: string(body)})
 	}
 
 	var data struct {
@@ -137,8 +631,19 @@ func GetAmadeusAccessToken(ctx context.Context) (string, error) {
 		TokenType   string `json:"token_type"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return "", err
+		return "", fmt.Errorf("amadeus: auth decode failed: %w", err)
+	}
+	if data.AccessToken == "" {
+		return "", errors.New("amadeus auth failed: empty access token")
 	}
 
+	lifetime := time.Duration(data.ExpiresIn) * time.Second
+	margin := min(tokenRefreshMargin, lifetime/2)
+
+	a.mu.Lock()
+	a.token = data.AccessToken
+	a.renewAt = time.Now().Add(lifetime - margin)
+	a.mu.Unlock()
+
 	return data.AccessToken, nil
 }
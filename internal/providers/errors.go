@@ -0,0 +1,15 @@
+package providers
+
+import "fmt"
+
+// StatusError is returned when a provider API answers with a non-2xx status.
+// Body holds the raw response for logs and must not be shown to clients.
+type StatusError struct {
+	Provider string
+	Code     int
+	Body     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d: %s", e.Provider, e.Code, e.Body)
+}
@@ -3,14 +3,16 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
-	"github.com/poportss/go-challenge-flight-price/internal/util"
 )
 
 // GoogleFlights provider
@@ -20,8 +22,14 @@ type GoogleFlights struct {
 	apiKey  string
 }
 
-func NewGoogleFlights(client *http.Client, apiKey string) *GoogleFlights {
-	base := util.EnvOr("GOOGLE_FLIGHTS_BASE_URL", "https://serpapi.com/search.json")
+// DefaultGoogleFlightsBaseURL is the SerpAPI search endpoint
+const DefaultGoogleFlightsBaseURL = "https://serpapi.com/search.json"
+
+func NewGoogleFlights(client *http.Client, baseURL, apiKey string) *GoogleFlights {
+	base := baseURL
+	if base == "" {
+		base = DefaultGoogleFlightsBaseURL
+	}
 	if !strings.HasPrefix(base, "http") {
 		base = "https://" + base
 	}
@@ -35,11 +43,10 @@ func NewGoogleFlights(client *http.Client, apiKey string) *GoogleFlights {
 func (g *GoogleFlights) Name() string { return "GoogleFlights" }
 
 // Search implements the Provider interface and fetches flight data from SerpAPI (Google Flights)
-func (g *GoogleFlights) Search(ctx context.Context, origin, destination string, startDate, endDate time.Time) ([]domain.Quote, error) {
-	url := fmt.Sprintf("%s?engine=google_flights&departure_id=%s&arrival_id=%s&outbound_date=%s&return_date=%s&currency=USD&hl=en&api_key=%s",
-		g.baseURL, origin, destination, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), g.apiKey)
+func (g *GoogleFlights) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	endpoint := g.baseURL + "?" + g.params(q).Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("googleflights: build request failed: %w", err)
 	}
@@ -52,7 +59,7 @@ func (g *GoogleFlights) Search(ctx context.Context, origin, destination string,
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("googleflights: bad status %d: %s", resp.StatusCode, string(body))
+		return nil, &StatusError{Provider: "googleflights", Code: resp.StatusCode, Body: string(body)}
 	}
 
 	var out domain.GoogleFlightsResponse
@@ -61,66 +68,148 @@ func (g *GoogleFlights) Search(ctx context.Context, origin, destination string,
 		return nil, fmt.Errorf("googleflights: decode failed: %w", err)
 	}
 
-	layout := "2006-01-02 15:04"
-	quotes := make([]domain.Quote, 0, len(out.BestFlights)+len(out.OtherFlights))
+	currency := q.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	options := append(out.BestFlights, out.OtherFlights...)
+	quotes := make([]domain.Quote, 0, len(options))
 
-	// process best flights
-	for _, f := range out.BestFlights {
-		if len(f.Flights) == 0 {
+	// best flights first, then the other (optional) flights
+	for _, f := range options {
+		outbound, err := googleItinerary(f)
+		if err != nil {
 			continue
 		}
-		first := f.Flights[0]
-		last := f.Flights[len(f.Flights)-1]
 
-		dep, err1 := time.Parse(layout, first.DepartureAirport.Time)
-		arr, err2 := time.Parse(layout, last.ArrivalAirport.Time)
-		if err1 != nil || err2 != nil {
+		// options without a price (sold out, price unavailable) can't be compared
+		price, err := domain.ParseMoney(f.Price.String(), currency)
+		if err != nil || price.IsZero() {
 			continue
 		}
 
+		// SerpAPI only prices the outbound here; return options need a departure_token round trip
 		quotes = append(quotes, domain.Quote{
-			Provider:    g.Name(),
-			Airline:     first.Airline,
-			Price:       f.Price,
-			Currency:    "USD",
-			DepartureAt: dep,
-			ArrivalAt:   arr,
-			Duration:    arr.Sub(dep),
-			Origin:      first.DepartureAirport.ID,
-			Destination: last.ArrivalAirport.ID,
+			Provider:       g.Name(),
+			Airline:        f.Flights[0].Airline,
+			Price:          price,
+			DepartureAt:    outbound.DepartureAt,
+			ArrivalAt:      outbound.ArrivalAt,
+			DepartureAtUTC: outbound.DepartureAt.UTC(),
+			ArrivalAtUTC:   outbound.ArrivalAt.UTC(),
+			Duration:       outbound.Duration,
+			Origin:         outbound.Origin,
+			Destination:    outbound.Destination,
+			Outbound:       &outbound,
 		})
 	}
 
-	// process other flights (optional)
-	for _, f := range out.OtherFlights {
-		if len(f.Flights) == 0 {
-			continue
-		}
-		first := f.Flights[0]
-		last := f.Flights[len(f.Flights)-1]
+	// no options is a valid answer; options that all failed to parse are not
+	if len(quotes) == 0 && len(options) > 0 {
+		return nil, fmt.Errorf("googleflights: no valid quotes parsed")
+	}
 
-		dep, err1 := time.Parse(layout, first.DepartureAirport.Time)
-		arr, err2 := time.Parse(layout, last.ArrivalAirport.Time)
-		if err1 != nil || err2 != nil {
-			continue
+	// SerpAPI has no result limit parameter, so trim locally
+	if q.MaxResults > 0 && len(quotes) > q.MaxResults {
+		quotes = quotes[:q.MaxResults]
+	}
+
+	return quotes, nil
+}
+
+// googleItinerary converts the flights of a SerpAPI option into an itinerary
+func googleItinerary(option domain.GoogleFlightsOption) (domain.Itinerary, error) {
+	flights := option.Flights
+	if len(flights) == 0 {
+		return domain.Itinerary{}, errors.New("googleflights: option without flights")
+	}
+
+	layout := "2006-01-02 15:04"
+	segments := make([]domain.Segment, 0, len(flights))
+	for _, f := range flights {
+		duration := time.Duration(f.Duration) * time.Minute
+
+		// SerpAPI returns local airport times without an offset
+		dep, arr, err := segmentTimes(layout, f.DepartureAirport.Time, f.DepartureAirport.ID, f.ArrivalAirport.Time, f.ArrivalAirport.ID, duration)
+		if err != nil {
+			return domain.Itinerary{}, fmt.Errorf("googleflights: invalid segment times %q → %q: %w", f.DepartureAirport.Time, f.ArrivalAirport.Time, err)
+		}
+		if duration <= 0 {
+			duration = arr.Sub(dep)
 		}
 
-		quotes = append(quotes, domain.Quote{
-			Provider:    g.Name(),
-			Airline:     first.Airline,
-			Price:       f.Price,
-			Currency:    "USD",
-			DepartureAt: dep,
-			ArrivalAt:   arr,
-			Duration:    arr.Sub(dep),
-			Origin:      first.DepartureAirport.ID,
-			Destination: last.ArrivalAirport.ID,
+		// flight numbers come as "LA 8180"; the prefix is the marketing carrier code
+		number := strings.ReplaceAll(f.FlightNumber, " ", "")
+		carrier, _, _ := strings.Cut(f.FlightNumber, " ")
+
+		segments = append(segments, domain.Segment{
+			Carrier:          carrier,
+			FlightNumber:     number,
+			OperatingCarrier: f.PlaneAndCrewBy,
+			Aircraft:         f.Airplane,
+			Origin:           f.DepartureAirport.ID,
+			DepartureAt:      dep,
+			Destination:      f.ArrivalAirport.ID,
+			ArrivalAt:        arr,
+			Duration:         duration,
 		})
 	}
 
-	if len(quotes) == 0 {
-		return nil, fmt.Errorf("googleflights: no valid quotes parsed")
+	itinerary := domain.NewItinerary(segments)
+	if option.TotalDuration > 0 {
+		itinerary.Duration = time.Duration(option.TotalDuration) * time.Minute
 	}
+	return itinerary, nil
+}
 
-	return quotes, nil
+// SerpAPI trip types
+const (
+	serpRoundTrip = "1"
+	serpOneWay    = "2"
+)
+
+// serpTravelClass maps cabins to SerpAPI travel_class values
+var serpTravelClass = map[string]string{
+	domain.CabinEconomy:        "1",
+	domain.CabinPremiumEconomy: "2",
+	domain.CabinBusiness:       "3",
+	domain.CabinFirst:          "4",
+}
+
+// params translates the query into SerpAPI google_flights parameters
+func (g *GoogleFlights) params(q domain.SearchQuery) url.Values {
+	v := url.Values{}
+	v.Set("engine", "google_flights")
+	v.Set("departure_id", q.Origin)
+	v.Set("arrival_id", q.Destination)
+	v.Set("outbound_date", q.DepartureDate.Format("2006-01-02"))
+	if q.OneWay() {
+		v.Set("type", serpOneWay)
+	} else {
+		v.Set("type", serpRoundTrip)
+		v.Set("return_date", q.ReturnDate.Format("2006-01-02"))
+	}
+	v.Set("adults", strconv.Itoa(max(q.Adults, 1)))
+	if q.Children > 0 {
+		v.Set("children", strconv.Itoa(q.Children))
+	}
+	// infants travel on an adult's lap, as with Amadeus' HELD_INFANT
+	if q.Infants > 0 {
+		v.Set("infants_on_lap", strconv.Itoa(q.Infants))
+	}
+	if tc, ok := serpTravelClass[q.Cabin]; ok {
+		v.Set("travel_class", tc)
+	}
+	if q.NonStop {
+		v.Set("stops", "1")
+	}
+	if q.Currency != "" {
+		v.Set("currency", q.Currency)
+	} else {
+		v.Set("currency", domain.DefaultCurrency)
+	}
+	v.Set("hl", "en")
+	v.Set("api_key", g.apiKey)
+	return v
 }
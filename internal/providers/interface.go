@@ -2,7 +2,6 @@ package providers
 
 import (
 	"context"
-	"time"
 
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
 )
@@ -10,5 +9,25 @@ import (
 // Provider interface for all flight providers
 type Provider interface {
 	Name() string
-	Search(ctx context.Context, origin, destination string, startDate, endDate time.Time) ([]domain.Quote, error)
+	Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error)
+}
+
+// MultiCityProvider is implemented by providers that can price multi-city (open-jaw) itineraries
+type MultiCityProvider interface {
+	Provider
+	SearchMultiCity(ctx context.Context, q domain.MultiCityQuery) ([]domain.Quote, error)
+}
+
+// CheapestDateProvider is implemented by providers that can find the cheapest
+// dates to fly a route within a window
+type CheapestDateProvider interface {
+	Provider
+	CheapestDates(ctx context.Context, q domain.CheapestDateQuery) ([]domain.DatePrice, error)
+}
+
+// DestinationProvider is implemented by providers that can list the
+// destinations reachable from an origin, e.g. within a budget
+type DestinationProvider interface {
+	Provider
+	Destinations(ctx context.Context, q domain.AnywhereQuery) ([]domain.DestinationPrice, error)
 }
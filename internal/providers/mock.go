@@ -2,9 +2,12 @@ package providers
 
 import (
 	"context"
+	"fmt"
+	"math"
 	"math/rand"
 	"time"
 
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
 	"github.com/poportss/go-challenge-flight-price/internal/domain"
 )
 
@@ -18,29 +21,205 @@ func NewMockProvider(name string) *MockProvider {
 
 func (m *MockProvider) Name() string { return m.name }
 
-func (m *MockProvider) Search(ctx context.Context, origin, destination string, startDate, endDate time.Time) ([]domain.Quote, error) {
+// cabinMultiplier roughly scales economy fares to the other cabins
+var cabinMultiplier = map[string]float64{
+	domain.CabinPremiumEconomy: 1.6,
+	domain.CabinBusiness:       3.5,
+	domain.CabinFirst:          6,
+}
+
+// localAt returns the given hour of the date in a local time zone
+func localAt(date time.Time, hour int, loc *time.Location) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, 0, 0, 0, loc)
+}
+
+// mockLocation returns the time zone of an airport, or of the first airport
+// of a city code
+func mockLocation(code string) (*time.Location, error) {
+	if loc, ok := airports.Location(code); ok {
+		return loc, nil
+	}
+	if city, ok := airports.CityAirports(code); ok {
+		if loc, ok := airports.Location(city[0].IATA); ok {
+			return loc, nil
+		}
+	}
+	return nil, fmt.Errorf("mock: %w: %s", airports.ErrUnknownTimezone, code)
+}
+
+func (m *MockProvider) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
 	// Simulate a variable response time (200–600ms)
-	time.Sleep(time.Duration(200+rand.Intn(400)) * time.Millisecond)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(200+rand.Intn(400)) * time.Millisecond):
+	}
 
-	// Simulate mock flight quotes
-	price := 500 + float64(rand.Intn(400))
-	duration := time.Duration(6+rand.Intn(4)) * time.Hour // 6–10 hours
+	from, err := mockLocation(q.Origin)
+	if err != nil {
+		return nil, err
+	}
+	to, err := mockLocation(q.Destination)
+	if err != nil {
+		return nil, err
+	}
+	currency := q.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
 
-	// Set departure and arrival times
-	departure := startDate.Add(10 * time.Hour) // 10 hours after start date
-	arrival := endDate.Add(-2 * time.Hour)     // 2 hours before end date
+	// Simulate mock flight quotes: one non-stop and, unless excluded, one with a connection
+	count := 2
+	if q.NonStop {
+		count = 1
+	}
+	if q.MaxResults > 0 && q.MaxResults < count {
+		count = q.MaxResults
+	}
 
-	return []domain.Quote{
-		{
-			Provider:    m.Name(),
-			Airline:     "MockAir",
-			Price:       price,
-			Currency:    "USD",
+	quotes := make([]domain.Quote, 0, count)
+	for i := 0; i < count; i++ {
+		fare := 500 + float64(rand.Intn(400))
+		if mult, ok := cabinMultiplier[q.Cabin]; ok {
+			fare *= mult
+		}
+		// infants on lap pay roughly 10% of the adult fare
+		price := fare*float64(max(q.Adults, 1)+q.Children) + fare*0.1*float64(q.Infants)
+
+		duration := time.Duration(6+rand.Intn(4)+i*3) * time.Hour // 6–10 hours, +3h per connection
+
+		// Depart at 10:00 local time and arrive in the destination's zone
+		departure := localAt(q.DepartureDate, 10, from)
+		arrival := departure.Add(duration).In(to)
+
+		quotes = append(quotes, domain.Quote{
+			Provider:       m.Name(),
+			Airline:        "MockAir",
+			Price:          domain.MoneyFromFloat(math.Round(price), currency),
+			DepartureAt:    departure,
+			ArrivalAt:      arrival,
+			DepartureAtUTC: departure.UTC(),
+			ArrivalAtUTC:   arrival.UTC(),
+			Duration:       duration,
+			Origin:         q.Origin,
+			Destination:    q.Destination,
+		})
+	}
+	return quotes, nil
+}
+
+// SearchMultiCity prices every leg independently and sums the fares
+func (m *MockProvider) SearchMultiCity(ctx context.Context, q domain.MultiCityQuery) ([]domain.Quote, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(200+rand.Intn(400)) * time.Millisecond):
+	}
+
+	currency := q.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	var total time.Duration
+	var price float64
+	itineraries := make([]domain.Itinerary, 0, len(q.Legs))
+	for _, l := range q.Legs {
+		fare := 300 + float64(rand.Intn(300))
+		if mult, ok := cabinMultiplier[q.Cabin]; ok {
+			fare *= mult
+		}
+		price += fare*float64(max(q.Adults, 1)+q.Children) + fare*0.1*float64(q.Infants)
+
+		from, err := mockLocation(l.Origin)
+		if err != nil {
+			return nil, err
+		}
+		to, err := mockLocation(l.Destination)
+		if err != nil {
+			return nil, err
+		}
+		duration := time.Duration(2+rand.Intn(8)) * time.Hour
+		departure := localAt(l.Date, 10, from)
+		itineraries = append(itineraries, domain.Itinerary{
+			Origin:      l.Origin,
+			Destination: l.Destination,
 			DepartureAt: departure,
-			ArrivalAt:   arrival,
+			ArrivalAt:   departure.Add(duration).In(to),
 			Duration:    duration,
-			Origin:      origin,
-			Destination: destination,
+		})
+		total += duration
+	}
+
+	return []domain.Quote{
+		{
+			Provider:       m.Name(),
+			Airline:        "MockAir",
+			Price:          domain.MoneyFromFloat(math.Round(price), currency),
+			DepartureAt:    itineraries[0].DepartureAt,
+			ArrivalAt:      itineraries[len(itineraries)-1].ArrivalAt,
+			DepartureAtUTC: itineraries[0].DepartureAt.UTC(),
+			ArrivalAtUTC:   itineraries[len(itineraries)-1].ArrivalAt.UTC(),
+			Duration:       total,
+			Origin:         q.Legs[0].Origin,
+			Destination:    q.Legs[len(q.Legs)-1].Destination,
+			Itineraries:    itineraries,
 		},
 	}, nil
 }
+
+// mockDestinations are the destinations the mock provider flies to
+var mockDestinations = []string{"JFK", "MIA", "LIS", "MAD", "CDG", "LHR", "FCO", "EZE", "SCL", "NRT"}
+
+// Destinations prices every mock destination by its distance from the origin,
+// keeping those within the budget
+func (m *MockProvider) Destinations(ctx context.Context, q domain.AnywhereQuery) ([]domain.DestinationPrice, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(time.Duration(200+rand.Intn(400)) * time.Millisecond):
+	}
+
+	origin, ok := airports.Lookup(q.Origin)
+	if !ok {
+		// fares from a city code are priced from its first airport
+		city, found := airports.CityAirports(q.Origin)
+		if !found {
+			return nil, fmt.Errorf("mock: unknown origin %s", q.Origin)
+		}
+		origin = city[0]
+	}
+	currency := q.MaxPrice.Currency
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+
+	dests := make([]domain.DestinationPrice, 0, len(mockDestinations))
+	for _, code := range mockDestinations {
+		a, ok := airports.Lookup(code)
+		if !ok || a.CityCode == origin.CityCode {
+			continue
+		}
+		// roughly 80 plus 8 cents per kilometer, half of it for one-way trips
+		fare := 80 + 0.08*airports.DistanceKm(origin, a)
+		if q.OneWay {
+			fare /= 2
+		}
+		price := domain.MoneyFromFloat(math.Round(fare), currency)
+		if !q.MaxPrice.IsZero() && q.MaxPrice.Less(price) {
+			continue
+		}
+
+		d := domain.DestinationPrice{
+			Destination:   a.IATA,
+			DepartureDate: q.From.Format("2006-01-02"),
+			Price:         price,
+			Provider:      m.Name(),
+		}
+		if !q.OneWay {
+			d.ReturnDate = q.From.AddDate(0, 0, q.MinDays).Format("2006-01-02")
+		}
+		dests = append(dests, d)
+	}
+	return dests, nil
+}
@@ -0,0 +1,125 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/util"
+)
+
+// Config describes a single provider instance to build at startup
+type Config struct {
+	Type         string   `json:"type"`           // registered kind: amadeus, googleflights, mock
+	Name         string   `json:"name,omitempty"` // display name, only used by the mock
+	Enabled      bool     `json:"enabled"`
+	BaseURL      string   `json:"base_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	APIKey       string   `json:"api_key,omitempty"`
+	Timeout      Duration `json:"timeout,omitempty"`
+}
+
+// Duration is a time.Duration that decodes from strings like "30s"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Factory builds a provider from its configuration
+type Factory func(cfg Config) (Provider, error)
+
+const defaultTimeout = 1 * time.Minute
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		"amadeus": func(cfg Config) (Provider, error) {
+			if cfg.ClientID == "" || cfg.ClientSecret == "" {
+				return nil, fmt.Errorf("amadeus: client_id and client_secret are required")
+			}
+			return NewAmadeus(util.NewHTTPClient(cfg.timeout()), cfg.BaseURL, cfg.ClientID, cfg.ClientSecret), nil
+		},
+		"googleflights": func(cfg Config) (Provider, error) {
+			if cfg.APIKey == "" {
+				return nil, fmt.Errorf("googleflights: api_key is required")
+			}
+			return NewGoogleFlights(util.NewHTTPClient(cfg.timeout()), cfg.BaseURL, cfg.APIKey), nil
+		},
+		"mock": func(cfg Config) (Provider, error) {
+			name := cfg.Name
+			if name == "" {
+				name = "Mock"
+			}
+			return NewMockProvider(name), nil
+		},
+	}
+)
+
+func (c Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(c.Timeout)
+}
+
+// Register adds or replaces the factory for a provider kind
+func Register(kind string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(kind)] = f
+}
+
+// Build instantiates every enabled provider, rejecting unknown kinds and duplicate names
+func Build(cfgs []Config) ([]Provider, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	out := make([]Provider, 0, len(cfgs))
+	seen := make(map[string]bool, len(cfgs))
+	for _, cfg := range cfgs {
+		if !cfg.Enabled {
+			continue
+		}
+		f, ok := registry[strings.ToLower(cfg.Type)]
+		if !ok {
+			return nil, fmt.Errorf("providers: unknown provider type %q (known: %s)", cfg.Type, strings.Join(kinds(), ", "))
+		}
+		p, err := f(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if seen[p.Name()] {
+			return nil, fmt.Errorf("providers: duplicate provider name %q", p.Name())
+		}
+		seen[p.Name()] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func kinds() []string {
+	ks := make([]string, 0, len(registry))
+	for k := range registry {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+)
+
+// segmentTimes parses the local departure and arrival times of a segment.
+// When one of the airports is missing from the reference data, its UTC offset
+// is worked out from the other end and the flight duration; without a
+// duration, or with both airports unknown, the times can't be placed and an
+// ErrUnknownTimezone error is returned.
+func segmentTimes(layout, depAt, depIATA, arrAt, arrIATA string, duration time.Duration) (time.Time, time.Time, error) {
+	dep, depErr := airports.ParseLocal(layout, depAt, depIATA)
+	arr, arrErr := airports.ParseLocal(layout, arrAt, arrIATA)
+	switch {
+	case depErr == nil && errors.Is(arrErr, airports.ErrUnknownTimezone) && duration > 0:
+		arr, arrErr = inferLocal(layout, arrAt, dep.Add(duration))
+	case arrErr == nil && errors.Is(depErr, airports.ErrUnknownTimezone) && duration > 0:
+		dep, depErr = inferLocal(layout, depAt, arr.Add(-duration))
+	}
+	return dep, arr, errors.Join(depErr, arrErr)
+}
+
+// inferLocal returns the wall-clock value in the UTC offset that puts it at
+// instant, rounded to the quarter hour every time zone uses
+func inferLocal(layout, value string, instant time.Time) (time.Time, error) {
+	wall, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	offset := wall.Sub(instant).Round(15 * time.Minute)
+	if offset < -12*time.Hour || offset > 14*time.Hour {
+		return time.Time{}, fmt.Errorf("%w: %s is %s away from any UTC offset", airports.ErrUnknownTimezone, value, offset)
+	}
+	zone := time.FixedZone("", int(offset/time.Second))
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, zone), nil
+}
@@ -0,0 +1,239 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+)
+
+// Searcher runs a flight search, flights.Service in production
+type Searcher interface {
+	Search(ctx context.Context, req domain.SearchRequest) (domain.AggregatedResponse, error)
+}
+
+// Update is the result of one poll of a route
+type Update struct {
+	Response domain.AggregatedResponse
+	Err      error
+	Version  string // flights.Version of the offers, empty on error
+	At       time.Time
+}
+
+// RouteStats reports the subscribers of one poller
+type RouteStats struct {
+	Route       string `json:"route"`
+	Interval    string `json:"interval"`
+	Subscribers int    `json:"subscribers"`
+}
+
+// Hub keeps one background poller per route, shared by all of its
+// subscribers and polling at the shortest interval any of them asked for. A
+// poller starts with the first subscriber and stops when the last one leaves.
+type Hub struct {
+	mu      sync.Mutex
+	search  Searcher
+	pollers map[string]*poller
+}
+
+type poller struct {
+	route    string
+	req      domain.SearchRequest
+	interval time.Duration // shortest interval of the subscribers
+	retune   chan struct{} // signals the run loop that interval changed
+	cancel   context.CancelFunc
+	subs     map[*Subscription]time.Duration
+	last     *Update
+}
+
+func NewHub(search Searcher) *Hub {
+	return &Hub{search: search, pollers: make(map[string]*poller)}
+}
+
+// Subscription receives the updates of one route. Only the latest update is
+// kept for slow readers. C is closed by Close.
+type Subscription struct {
+	C <-chan Update
+
+	ch  chan Update
+	hub *Hub
+	key string
+}
+
+// Subscribe registers for updates of the route, starting its poller if needed.
+// A subscriber joining a running poller immediately gets its latest update.
+func (h *Hub) Subscribe(req domain.SearchRequest, interval time.Duration) *Subscription {
+	route := Route(req)
+	key := pollerKey(req)
+
+	ch := make(chan Update, 1)
+	sub := &Subscription{C: ch, ch: ch, hub: h, key: key}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, ok := h.pollers[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.Background())
+		p = &poller{
+			route:    route,
+			req:      req,
+			interval: interval,
+			retune:   make(chan struct{}, 1),
+			cancel:   cancel,
+			subs:     make(map[*Subscription]time.Duration),
+		}
+		h.pollers[key] = p
+		go h.run(ctx, p, interval)
+		log.Printf("✓ Poller started for %s every %s", route, interval)
+	}
+	p.subs[sub] = interval
+	p.adjust()
+	if p.last != nil {
+		ch <- *p.last
+	}
+	return sub
+}
+
+// Close unsubscribes, stopping the poller if this was its last subscriber
+func (s *Subscription) Close() {
+	h := s.hub
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	p, ok := h.pollers[s.key]
+	if !ok {
+		return
+	}
+	if _, subscribed := p.subs[s]; !subscribed {
+		return
+	}
+	delete(p.subs, s)
+	close(s.ch)
+
+	if len(p.subs) == 0 {
+		p.cancel()
+		delete(h.pollers, s.key)
+		log.Printf("✓ Poller stopped for %s", p.route)
+		return
+	}
+	p.adjust()
+}
+
+// adjust sets the poll interval to the shortest one of the subscribers and
+// wakes the run loop when it changed; the caller holds the hub lock
+func (p *poller) adjust() {
+	shortest := time.Duration(0)
+	for _, d := range p.subs {
+		if shortest == 0 || d < shortest {
+			shortest = d
+		}
+	}
+	if shortest == p.interval {
+		return
+	}
+	log.Printf("✓ Poller for %s now every %s", p.route, shortest)
+	p.interval = shortest
+	select {
+	case p.retune <- struct{}{}:
+	default:
+	}
+}
+
+// Stats returns the subscriber count of every running poller
+func (h *Hub) Stats() []RouteStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	stats := make([]RouteStats, 0, len(h.pollers))
+	for _, p := range h.pollers {
+		stats = append(stats, RouteStats{Route: p.route, Interval: p.interval.String(), Subscribers: len(p.subs)})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Route < stats[j].Route })
+	return stats
+}
+
+func (h *Hub) run(ctx context.Context, p *poller, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := h.search.Search(ctx, p.req)
+		if ctx.Err() != nil {
+			return
+		}
+		h.publish(p, resp, err)
+
+		if !h.wait(ctx, p, ticker) {
+			return
+		}
+	}
+}
+
+// wait blocks until the next poll is due, following interval changes, and
+// returns false once the poller is stopped
+func (h *Hub) wait(ctx context.Context, p *poller, ticker *time.Ticker) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-p.retune:
+			h.mu.Lock()
+			interval := p.interval
+			h.mu.Unlock()
+			ticker.Reset(interval)
+		case <-ticker.C:
+			return true
+		}
+	}
+}
+
+// publish sends the poll result to every subscriber unless the offers are
+// unchanged since the last update
+func (h *Hub) publish(p *poller, resp domain.AggregatedResponse, err error) {
+	u := Update{Response: resp, Err: err, At: time.Now()}
+	if err == nil {
+		u.Version = flights.Version(resp.Offers)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err == nil && p.last != nil && p.last.Version == u.Version {
+		return
+	}
+	p.last = &u
+	for sub := range p.subs {
+		// keep only the latest update for subscribers that fall behind
+		select {
+		case sub.ch <- u:
+		default:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			sub.ch <- u
+		}
+	}
+}
+
+// Route formats a search request as ORIGIN|DESTINATION|YYYY-MM-DD[|YYYY-MM-DD]
+func Route(req domain.SearchRequest) string {
+	parts := []string{strings.ToUpper(req.Origin), strings.ToUpper(req.Destination), req.StartDate.Format("2006-01-02")}
+	if !req.EndDate.IsZero() {
+		parts = append(parts, req.EndDate.Format("2006-01-02"))
+	}
+	return strings.Join(parts, "|")
+}
+
+// pollerKey identifies the searches that can share a poller: same query and
+// options
+func pollerKey(req domain.SearchRequest) string {
+	return fmt.Sprintf("%s|nearby=%g", strings.ToUpper(req.Query().Key()), req.NearbyKm)
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// countingProv counts how many searches reach it
+type countingProv struct {
+	calls *atomic.Int32
+}
+
+func (c countingProv) Name() string { return "counting" }
+func (c countingProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	c.calls.Add(1)
+	return []domain.Quote{{Provider: "counting", Price: domain.NewMoney(10000, "USD"), Origin: q.Origin, Destination: q.Destination}}, nil
+}
+
+func TestAirportReferenceData(t *testing.T) {
+	a, ok := airports.Lookup("lhr")
+	if !ok || a.CityCode != "LON" || a.Country != "GB" || a.Timezone != "Europe/London" {
+		t.Fatalf("unexpected LHR data %+v", a)
+	}
+	if code, err := airports.Normalize(" nyc "); err != nil || code != "NYC" {
+		t.Fatalf("expected city code NYC to be valid, got %q %v", code, err)
+	}
+	for _, code := range []string{"XYZ", "X1Z", ""} {
+		if _, err := airports.Normalize(code); !errors.Is(err, airports.ErrInvalidCode) {
+			t.Fatalf("expected ErrInvalidCode for %q, got %v", code, err)
+		}
+	}
+
+	// every code that validates must also resolve to a time zone
+	for _, code := range []string{"AUS", "KBP", "NLU", "CTS", "FEN", "POM"} {
+		if _, err := airports.Normalize(code); err != nil {
+			t.Fatalf("expected %s to be known, got %v", code, err)
+		}
+		if _, err := airports.ParseLocal("2006-01-02T15:04", "2026-12-01T10:00", code); err != nil {
+			t.Fatalf("expected a time zone for %s, got %v", code, err)
+		}
+	}
+}
+
+func TestAirportsLoadFileKeepsDataOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "airports.csv")
+	csv := "iata,name,city,city_code,country,latitude,longitude,timezone\n" +
+		"ZZZ,Nowhere Airport,Nowhere,ZZZ,XX,1.0,2.0,Mars/Olympus\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before := airports.Count()
+	if err := airports.LoadFile(path); err == nil {
+		t.Fatal("expected an unknown time zone to be rejected")
+	}
+	if airports.Count() != before {
+		t.Fatalf("expected the embedded data to be kept, got %d airports instead of %d", airports.Count(), before)
+	}
+	if _, err := airports.Normalize("ZZZ"); !errors.Is(err, airports.ErrInvalidCode) {
+		t.Fatalf("expected ZZZ to stay unknown, got %v", err)
+	}
+}
+
+func TestCityCodes(t *testing.T) {
+	for code, want := range map[string]string{
+		"IST": "IST SAW", // both an airport and the city it serves
+		"BKK": "BKK DMK",
+		"SFO": "SFO", // OAK and SJC are cities of their own
+		"OAK": "OAK",
+		"MIA": "MIA",
+		"XYZ": "", // unknown codes stand for nothing
+	} {
+		got := airports.Expand(code)
+		slices.Sort(got)
+		w := strings.Fields(want)
+		slices.Sort(w)
+		if !slices.Equal(got, w) {
+			t.Fatalf("expected %s to expand to %v, got %v", code, w, got)
+		}
+	}
+	if a, ok := airports.Lookup("FLL"); !ok || a.CityCode != "FLL" {
+		t.Fatalf("expected FLL to be a city of its own, got %+v", a)
+	}
+}
+
+func TestSearchValidatesAndNormalizesLocations(t *testing.T) {
+	calls := &atomic.Int32{}
+	svc := flights.NewService([]providers.Provider{countingProv{calls: calls}}, 5*time.Second, flights.NewInMemoryTTL())
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, origin := range []string{"XYZ", "X1Z"} {
+		_, err := svc.Search(context.Background(), domain.SearchRequest{Origin: origin, Destination: "JFK", StartDate: start})
+		if !errors.Is(err, flights.ErrInvalidLocation) {
+			t.Fatalf("expected ErrInvalidLocation for %s, got %v", origin, err)
+		}
+	}
+	if calls.Load() != 0 {
+		t.Fatal("invalid searches must not reach providers")
+	}
+
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{Origin: "gru", Destination: "jfk", StartDate: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Search(context.Background(), domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: start}); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected lowercase and uppercase searches to share a cache entry, got %d calls", calls.Load())
+	}
+
+	if resp.Airports["GRU"].City != "São Paulo" || resp.Airports["JFK"].CityCode != "NYC" {
+		t.Fatalf("expected enriched airport info, got %+v", resp.Airports)
+	}
+}
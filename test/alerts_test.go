@@ -0,0 +1,217 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/alerts"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/http/middleware"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// priceSearcher answers with whatever cheapest price is set
+type priceSearcher struct{ minor atomic.Int64 }
+
+func (p *priceSearcher) Search(ctx context.Context, req domain.SearchRequest) (domain.AggregatedResponse, error) {
+	q := domain.Quote{Provider: "p", Price: domain.NewMoney(p.minor.Load(), req.Currency)}
+	return domain.AggregatedResponse{Cheapest: &q, Fastest: &q, Offers: []domain.Quote{q}}, nil
+}
+
+// webhookRecorder collects the verified notifications, failing the first `fail` deliveries
+type webhookRecorder struct {
+	mu     sync.Mutex
+	secret string
+	fail   int
+	got    []domain.AlertNotification
+}
+
+func (w *webhookRecorder) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if r.Header.Get(alerts.SignatureHeader) != alerts.Sign(w.secret, body) {
+		rw.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if w.fail > 0 {
+		w.fail--
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var n domain.AlertNotification
+	_ = json.Unmarshal(body, &n)
+	w.got = append(w.got, n)
+}
+
+func (w *webhookRecorder) count() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.got)
+}
+
+func TestAlertSchedulerNotifiesOncePerDrop(t *testing.T) {
+	hook := &webhookRecorder{secret: "0123456789abcdef", fail: 1}
+	srv := httptest.NewServer(hook)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	store, err := alerts.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := store.Create(ctx, domain.Alert{
+		ID: "a1", User: "admin", Active: true, Secret: hook.secret, WebhookURL: srv.URL,
+		Search: domain.SearchParams{Origin: "GRU", Destination: "LIS", StartDate: "2025-12-10"},
+		Below:  domain.NewMoney(60000, "USD"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	searcher := &priceSearcher{}
+	sched := alerts.NewScheduler(store, searcher, alerts.NewWebhook(srv.Client(), 3, time.Millisecond), time.Hour)
+
+	steps := []struct {
+		minor int64
+		want  int // notifications so far
+	}{
+		{65000, 0}, // above the threshold
+		{59000, 1}, // drops below: notified after one retried 503
+		{59000, 1}, // same drop, not reported again
+		{55000, 2}, // further drop
+		{61000, 2}, // back above: re-arms
+		{58000, 3}, // new drop
+	}
+	for i, st := range steps {
+		searcher.minor.Store(st.minor)
+		sched.RunOnce(ctx)
+		if got := hook.count(); got != st.want {
+			t.Fatalf("step %d (%d): expected %d notifications, got %d", i, st.minor, st.want, got)
+		}
+	}
+
+	last := hook.got[len(hook.got)-1]
+	if last.AlertID != "a1" || last.Price != domain.NewMoney(58000, "USD") || last.Event != domain.AlertPriceDrop {
+		t.Fatalf("unexpected notification %+v", last)
+	}
+
+	// the dedupe state survives a restart
+	reopened, err := alerts.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := reopened.Get(ctx, "a1")
+	if err != nil || a.NotifiedPrice == nil || *a.NotifiedPrice != domain.NewMoney(58000, "USD") {
+		t.Fatalf("expected the notified price to be stored, got %+v (%v)", a, err)
+	}
+}
+
+func TestAlertsCRUD(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{fakeProv{name: "p"}}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret", httpserver.WithAlerts(alerts.NewMemoryStore())).Engine()
+
+	do := func(method, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	bad := do("POST", "/alerts", gin.H{
+		"search": gin.H{"origin": "XYZ", "destination": "LIS", "startDate": "2025-12-10"},
+		"below":  gin.H{"amount": "600", "currency": "USD"}, "webhookUrl": "https://example.com/hook",
+	})
+	if bad.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an unknown airport, got %d", bad.Code)
+	}
+
+	w := do("POST", "/alerts", gin.H{
+		"search": gin.H{"origin": "GRU", "destination": "LIS", "startDate": "2025-12-10"},
+		"below":  gin.H{"amount": "600", "currency": "USD"}, "webhookUrl": "https://example.com/hook",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var created domain.Alert
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Secret == "" || created.User != "tester" || !created.Active {
+		t.Fatalf("unexpected alert %+v", created)
+	}
+
+	for _, hook := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080/hook", "http://10.0.0.5/hook", "ftp://example.com/hook"} {
+		if w := do("PATCH", "/alerts/"+created.ID, gin.H{"webhookUrl": hook}); w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %s, got %d", hook, w.Code)
+		}
+	}
+
+	w = do("PATCH", "/alerts/"+created.ID, gin.H{"active": false})
+	var updated domain.Alert
+	_ = json.Unmarshal(w.Body.Bytes(), &updated)
+	if w.Code != http.StatusOK || updated.Active || updated.Secret != "" {
+		t.Fatalf("unexpected update %d %+v", w.Code, updated)
+	}
+
+	if w = do("GET", "/alerts", nil); w.Code != http.StatusOK || bytes.Contains(w.Body.Bytes(), []byte(created.Secret)) {
+		t.Fatalf("expected the list without secrets, got %d %s", w.Code, w.Body.String())
+	}
+	if w = do("DELETE", "/alerts/"+created.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+	if w = do("GET", "/alerts/"+created.ID, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 after delete, got %d", w.Code)
+	}
+}
+
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	hook := &webhookRecorder{secret: "0123456789abcdef"}
+	srv := httptest.NewServer(hook) // listens on loopback
+	defer srv.Close()
+
+	alert := domain.Alert{ID: "a1", Secret: hook.secret, WebhookURL: srv.URL}
+	webhook := alerts.NewWebhook(alerts.NewWebhookClient(time.Second), 1, time.Millisecond)
+	err := webhook.Notify(context.Background(), alert, domain.AlertNotification{Event: "price_drop"})
+	if !errors.Is(err, alerts.ErrPrivateAddress) {
+		t.Fatalf("expected the loopback webhook to be refused, got %v", err)
+	}
+	if hook.count() != 0 {
+		t.Fatal("expected no delivery")
+	}
+}
+
+func TestTokenWithoutUserIsRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{fakeProv{name: "p"}}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret", httpserver.WithAlerts(alerts.NewMemoryStore())).Engine()
+
+	token, err := middleware.GenerateJWT("secret", "", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/alerts", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,157 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+const amadeusOffersFixture = `{"data":[{"itineraries":[{"segments":[
+	{"departure":{"iataCode":"GRU","at":"2025-12-01T22:00:00"},
+	 "arrival":{"iataCode":"JFK","at":"2025-12-02T06:00:00"},
+	 "carrierCode":"LA","number":"8180"}]}],
+	"price":{"currency":"USD","grandTotal":"812.40"}}]}`
+
+// amadeusStub serves the token and flight-offers endpoints and counts token requests
+type amadeusStub struct {
+	tokenCalls  atomic.Int32
+	expiresIn   int
+	reject      atomic.Int32 // number of offer requests to answer with 401
+	revoked     string       // token whose offer requests are answered with 401, one every 10ms
+	revokedHits atomic.Int32
+}
+
+func (s *amadeusStub) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		n := s.tokenCalls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"expires_in":   s.expiresIn,
+			"token_type":   "Bearer",
+		})
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		if s.reject.Load() > 0 {
+			s.reject.Add(-1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if s.revoked != "" && r.Header.Get("Authorization") == "Bearer "+s.revoked {
+			// spread the rejections past the 20ms token refresh
+			time.Sleep(time.Duration(s.revokedHits.Add(1)) * 10 * time.Millisecond)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(amadeusOffersFixture))
+	})
+	return mux
+}
+
+func newAmadeusStub(t *testing.T, expiresIn int) (*amadeusStub, *providers.Amadeus) {
+	t.Helper()
+	stub := &amadeusStub{expiresIn: expiresIn}
+	srv := httptest.NewServer(stub.handler())
+	t.Cleanup(srv.Close)
+	return stub, providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+}
+
+func TestAmadeusTokenSharedAcrossConcurrentSearches(t *testing.T) {
+	stub, a := newAmadeusStub(t, 1799)
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	query := domain.SearchQuery{Origin: "GRU", Destination: "JFK", DepartureDate: start, ReturnDate: start.AddDate(0, 0, 9)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := a.Search(context.Background(), query); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := stub.tokenCalls.Load(); got != 1 {
+		t.Fatalf("expected 1 token request, got %d", got)
+	}
+}
+
+func TestAmadeusTokenRefreshedBeforeExpiry(t *testing.T) {
+	// a token living less than the refresh margin is still reused for the
+	// first half of its life, then renewed ahead of its expiry
+	stub, a := newAmadeusStub(t, 1)
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.AccessToken(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := stub.tokenCalls.Load(); got != 1 {
+		t.Fatalf("expected the short-lived token to be reused, got %d token requests", got)
+	}
+
+	time.Sleep(600 * time.Millisecond)
+	if _, err := a.AccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := stub.tokenCalls.Load(); got != 2 {
+		t.Fatalf("expected the token to be renewed halfway through its life, got %d token requests", got)
+	}
+}
+
+func TestAmadeusTokenRefreshedOnUnauthorized(t *testing.T) {
+	stub, a := newAmadeusStub(t, 1799)
+	stub.reject.Store(1)
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.SearchQuery{Origin: "GRU", Destination: "JFK", DepartureDate: start, ReturnDate: start.AddDate(0, 0, 9)}
+
+	qs, err := a.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 {
+		t.Fatalf("expected 1 quote, got %d", len(qs))
+	}
+	if got := stub.tokenCalls.Load(); got != 2 {
+		t.Fatalf("expected 2 token requests, got %d", got)
+	}
+}
+
+func TestAmadeusConcurrentUnauthorizedRenewsOnce(t *testing.T) {
+	// every search starts with the revoked token-1: the first 401 renews it
+	// and the later ones must reuse token-2 instead of discarding it
+	stub, a := newAmadeusStub(t, 1799)
+	stub.revoked = "token-1"
+	if _, err := a.AccessToken(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	query := domain.SearchQuery{Origin: "GRU", Destination: "JFK", DepartureDate: start, ReturnDate: start.AddDate(0, 0, 9)}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := a.Search(context.Background(), query); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got := stub.tokenCalls.Load(); got != 2 {
+		t.Fatalf("expected 2 token requests, got %d", got)
+	}
+}
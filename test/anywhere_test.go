@@ -0,0 +1,174 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+const amadeusFlightDestinationsFixture = `{"meta":{"currency":"EUR"},"data":[
+	{"type":"flight-destination","origin":"GRU","destination":"PAR","departureDate":"2025-12-06","returnDate":"2025-12-13","price":{"total":"250.00"}},
+	{"type":"flight-destination","origin":"GRU","destination":"EZE","departureDate":"2025-12-02","returnDate":"2025-12-09","price":{"total":"100.00"}},
+	{"type":"flight-destination","origin":"GRU","destination":"JFK","departureDate":"2025-12-03","returnDate":"2025-12-10","price":{"total":"400.00"}}]}`
+
+// amadeusDestinationsStub serves the token and flight-destinations endpoints
+// and remembers the last destinations query
+func amadeusDestinationsStub(t *testing.T, query *map[string]string) *providers.Amadeus {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 1799})
+	})
+	mux.HandleFunc("/v1/shopping/flight-destinations", func(w http.ResponseWriter, r *http.Request) {
+		*query = map[string]string{}
+		for k := range r.URL.Query() {
+			(*query)[k] = r.URL.Query().Get(k)
+		}
+		_, _ = w.Write([]byte(amadeusFlightDestinationsFixture))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+}
+
+func anywhere(t *testing.T, engine *gin.Engine, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/flights/anywhere?"+query, nil)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestAnywhereWithinBudget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var query map[string]string
+	amadeus := amadeusDestinationsStub(t, &query)
+	svc := flights.NewService([]providers.Provider{amadeus, fakeProv{name: "search-only"}}, 5*time.Second, flights.NewInMemoryTTL())
+	rates := httptest.NewServer(fx.StubHandler(testRates))
+	defer rates.Close()
+	svc.SetRateSource(fx.NewHTTPSource(rates.Client(), rates.URL, time.Minute))
+	engine := httpserver.New(svc, "secret").Engine()
+
+	w := anywhere(t, engine, "origin=gru&from=2025-12-01&to=2025-12-31&maxPrice=600&minDays=5&maxDays=9")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if query["origin"] != "GRU" || query["departureDate"] != "2025-12-01,2025-12-31" || query["duration"] != "5,9" || query["viewBy"] != "DESTINATION" {
+		t.Fatalf("unexpected amadeus query %v", query)
+	}
+
+	var resp domain.AnywhereResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// 100 EUR and 250 EUR are 200 and 500 USD; 400 EUR is over budget
+	if len(resp.Destinations) != 2 {
+		t.Fatalf("expected 2 destinations within budget, got %+v", resp.Destinations)
+	}
+	eze, par := resp.Destinations[0], resp.Destinations[1]
+	if eze.Destination != "EZE" || eze.Price != domain.NewMoney(20000, "USD") || eze.City != "Buenos Aires" || eze.Country != "AR" {
+		t.Fatalf("unexpected first destination %+v", eze)
+	}
+	if par.Destination != "PAR" || par.Price != domain.NewMoney(50000, "USD") || par.City != "Paris" {
+		t.Fatalf("unexpected second destination %+v", par)
+	}
+}
+
+func TestAnywhereWithMockProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{providers.NewMockProvider("Mock")}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	w := anywhere(t, engine, "origin=GRU&from=2025-12-01&to=2025-12-31&maxPrice=500&limit=3")
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp domain.AnywhereResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Destinations) == 0 || len(resp.Destinations) > 3 {
+		t.Fatalf("expected 1 to 3 destinations, got %+v", resp.Destinations)
+	}
+	budget := domain.NewMoney(50000, "USD")
+	for i, d := range resp.Destinations {
+		if budget.Less(d.Price) {
+			t.Fatalf("%s is over budget: %s", d.Destination, d.Price)
+		}
+		if i > 0 && d.Price.Less(resp.Destinations[i-1].Price) {
+			t.Fatalf("destinations not sorted by price: %+v", resp.Destinations)
+		}
+		if d.Name == "" {
+			t.Fatalf("expected %s to be described", d.Destination)
+		}
+	}
+}
+
+func TestAnywhereUnsupported(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{fakeProv{name: "search-only"}}, time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	if w := anywhere(t, engine, "origin=GRU&from=2025-12-01&to=2025-12-31&maxPrice=600"); w.Code != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := anywhere(t, engine, "origin=GRU&from=2025-12-01&to=2025-12-31&maxPrice=-5"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a negative budget, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMockDestinationsFromCityCode(t *testing.T) {
+	mock := providers.NewMockProvider("Mock")
+	for _, origin := range []string{"LON", "SAO"} {
+		dests, err := mock.Destinations(context.Background(), domain.AnywhereQuery{Origin: origin, From: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), MinDays: 7})
+		if err != nil {
+			t.Fatalf("%s: %v", origin, err)
+		}
+		if len(dests) == 0 {
+			t.Fatalf("%s: expected destinations", origin)
+		}
+		for _, d := range dests {
+			if a, _ := airports.Lookup(d.Destination); a.CityCode == origin {
+				t.Fatalf("%s: expected no destination in the origin city, got %s", origin, d.Destination)
+			}
+		}
+	}
+}
+
+func TestAnywhereHidesProviderErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 1799})
+	})
+	mux.HandleFunc("/v1/shopping/flight-destinations", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors":[{"detail":"secret upstream payload"}]}`, http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	amadeus := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	svc := flights.NewService([]providers.Provider{amadeus}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	w := anywhere(t, engine, "origin=GRU&from=2026-12-01&to=2026-12-31&maxPrice=600")
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("expected 502, got %d: %s", w.Code, w.Body.String())
+	}
+	if body := w.Body.String(); strings.Contains(body, "secret upstream payload") || !strings.Contains(body, "Amadeus: provider returned status 500") {
+		t.Fatalf("expected only the classified provider error, got %s", body)
+	}
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/cache"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.New[string, int](cache.Options[int]{MaxEntries: 2})
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, time.Minute)
+	if _, ok := c.Get("a"); !ok { // a is now more recent than b
+		t.Fatal("expected a to be cached")
+	}
+	c.Set("c", 3, time.Minute)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Fatalf("expected a=1, got %d %v", v, ok)
+	}
+	st := c.Stats()
+	if st.Hits != 2 || st.Misses != 1 || st.Evictions != 1 || st.Entries != 2 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestCacheMaxBytes(t *testing.T) {
+	c := cache.New[string, string](cache.Options[string]{
+		MaxBytes: 10,
+		Size:     func(s string) int64 { return int64(len(s)) },
+	})
+	c.Set("a", "aaaa", time.Minute)
+	c.Set("b", "bbbb", time.Minute)
+	c.Set("c", "cccc", time.Minute) // 12 bytes: a goes
+	c.Set("huge", "xxxxxxxxxxxx", time.Minute)
+
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("expected a to be evicted")
+	}
+	if _, ok := c.Get("huge"); ok {
+		t.Fatal("expected a value over MaxBytes not to be stored")
+	}
+	if st := c.Stats(); st.Bytes != 8 || st.Entries != 2 || st.Evictions != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+}
+
+func TestCacheExpiryAndCleanup(t *testing.T) {
+	c := cache.New[string, int](cache.Options[int]{})
+	c.Set("short", 1, 10*time.Millisecond)
+	c.Set("long", 2, time.Minute)
+
+	before := runtime.NumGoroutine()
+	c.StartCleanup(5 * time.Millisecond)
+	c.StartCleanup(5 * time.Millisecond) // already running: no second goroutine
+	time.Sleep(50 * time.Millisecond)
+
+	if n := c.Len(); n != 1 {
+		t.Fatalf("expected the expired entry to be cleaned up, got %d entries", n)
+	}
+	if st := c.Stats(); st.Expired != 1 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	c.Stop()
+	c.Stop()
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("expected the cleanup goroutine to stop, %d goroutines before and %d after", before, after)
+	}
+}
+
+func TestServiceUsesTypedCache(t *testing.T) {
+	prov := &datePricedProv{}
+	c := flights.NewResponseCache(10, 0)
+	svc := flights.NewService([]providers.Provider{prov}, time.Second, c)
+
+	req := domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC)}
+	for range 3 {
+		if _, err := svc.Search(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := prov.calls.Load(); n != 1 {
+		t.Fatalf("expected one provider call, got %d", n)
+	}
+	st, ok := svc.CacheStats()
+	if !ok || st.Hits != 2 || st.Misses != 1 || st.Entries != 1 || st.Bytes == 0 {
+		t.Fatalf("unexpected cache stats %+v", st)
+	}
+}
@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// datePricedProv prices trips by their dates and tracks concurrent calls
+type datePricedProv struct {
+	calls, inFlight, peak atomic.Int64
+}
+
+func (p *datePricedProv) Name() string { return "dated" }
+func (p *datePricedProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	p.calls.Add(1)
+	n := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// cheapest when leaving on the 2nd and coming back on the 11th
+	price := 50000 + 1000*abs(q.DepartureDate.Day()-2) + 500*abs(q.ReturnDate.Day()-11)
+	return []domain.Quote{{Provider: "dated", Price: domain.NewMoney(int64(price), "USD")}}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func TestCalendarMatrix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := &datePricedProv{}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	year := time.Now().Year() + 1
+	get := func() domain.PriceCalendar {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", fmt.Sprintf("/flights/calendar?origin=GRU&destination=JFK&starDate=%d-12-03&endDate=%d-12-10&departFlex=2&returnFlex=1", year, year), nil)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		engine.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var cal domain.PriceCalendar
+		if err := json.Unmarshal(w.Body.Bytes(), &cal); err != nil {
+			t.Fatal(err)
+		}
+		return cal
+	}
+
+	cal := get()
+	if len(cal.DepartureDates) != 5 || len(cal.ReturnDates) != 3 || len(cal.Cells) != 5 || len(cal.Cells[0]) != 3 {
+		t.Fatalf("expected a 5x3 matrix, got %v × %v", cal.DepartureDates, cal.ReturnDates)
+	}
+	if cal.Cheapest == nil || cal.Cheapest.DepartureDate != fmt.Sprintf("%d-12-02", year) || cal.Cheapest.ReturnDate != fmt.Sprintf("%d-12-11", year) {
+		t.Fatalf("unexpected cheapest cell %+v", cal.Cheapest)
+	}
+	if !cal.Cells[1][2].Cheapest || *cal.Cells[1][2].Price != domain.NewMoney(50000, "USD") {
+		t.Fatalf("expected the cheapest cell to be highlighted, got %+v", cal.Cells[1][2])
+	}
+	if peak := prov.peak.Load(); peak > 4 {
+		t.Fatalf("expected at most 4 concurrent searches, got %d", peak)
+	}
+
+	calls := prov.calls.Load()
+	get()
+	if prov.calls.Load() != calls {
+		t.Fatal("expected the second calendar to be served from the cache")
+	}
+}
+
+func TestCalendarSkipsPastDatesAndCapsSearches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := &datePricedProv{}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/flights/calendar?"+query, nil)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	today := time.Now().UTC()
+	w := get("origin=GRU&destination=JFK&departFlex=3&starDate=" + today.AddDate(0, 0, 1).Format("2006-01-02"))
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var cal domain.PriceCalendar
+	if err := json.Unmarshal(w.Body.Bytes(), &cal); err != nil {
+		t.Fatal(err)
+	}
+	// yesterday and the day before are left out
+	if len(cal.DepartureDates) != 5 || cal.DepartureDates[0] != today.Format("2006-01-02") || prov.calls.Load() != 5 {
+		t.Fatalf("expected only the 5 dates from today, got %v and %d searches", cal.DepartureDates, prov.calls.Load())
+	}
+
+	if w := get("origin=GRU&destination=JFK&starDate=2020-01-10"); w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a calendar in the past, got %d", w.Code)
+	}
+
+	// 7 × 7 dates to the 3 airports of New York is 147 searches
+	next := today.AddDate(0, 1, 0)
+	w = get("origin=GRU&destination=NYC&departFlex=3&returnFlex=3&starDate=" + next.Format("2006-01-02") + "&endDate=" + next.AddDate(0, 0, 7).Format("2006-01-02"))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "147") {
+		t.Fatalf("expected 400 for too many searches, got %d: %s", w.Code, w.Body.String())
+	}
+	if prov.calls.Load() != 5 {
+		t.Fatalf("expected no provider call for the rejected calendar, got %d", prov.calls.Load())
+	}
+}
@@ -0,0 +1,198 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// gatedProv answers once released, or reports that its context was canceled
+type gatedProv struct {
+	calls    atomic.Int64
+	started  chan struct{}
+	release  chan struct{}
+	canceled chan struct{}
+}
+
+func newGatedProv() *gatedProv {
+	return &gatedProv{started: make(chan struct{}, 16), release: make(chan struct{}), canceled: make(chan struct{}, 16)}
+}
+
+func (p *gatedProv) Name() string { return "gated" }
+func (p *gatedProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	p.calls.Add(1)
+	p.started <- struct{}{}
+	select {
+	case <-p.release:
+		return []domain.Quote{{Provider: "gated", Price: domain.NewMoney(50000, "USD")}}, nil
+	case <-ctx.Done():
+		p.canceled <- struct{}{}
+		return nil, ctx.Err()
+	}
+}
+
+var coalescedReq = domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+func TestConcurrentSearchesShareOneFanOut(t *testing.T) {
+	prov := newGatedProv()
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, noCache{})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan domain.AggregatedResponse, callers)
+	for range callers {
+		wg.Go(func() {
+			resp, err := svc.Search(context.Background(), coalescedReq)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results <- resp
+		})
+	}
+
+	<-prov.started
+	time.Sleep(50 * time.Millisecond) // let every caller join
+	close(prov.release)
+	wg.Wait()
+	close(results)
+
+	if n := prov.calls.Load(); n != 1 {
+		t.Fatalf("expected one provider call, got %d", n)
+	}
+	count := 0
+	for resp := range results {
+		if resp.Cheapest == nil || resp.Cheapest.Price != domain.NewMoney(50000, "USD") {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		count++
+	}
+	if count != callers {
+		t.Fatalf("expected %d responses, got %d", callers, count)
+	}
+}
+
+func TestCoalescedSearchSurvivesOneCallerLeaving(t *testing.T) {
+	prov := newGatedProv()
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, noCache{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := svc.Search(ctx, coalescedReq)
+		first <- err
+	}()
+	<-prov.started
+
+	second := make(chan domain.AggregatedResponse, 1)
+	go func() {
+		resp, _ := svc.Search(context.Background(), coalescedReq)
+		second <- resp
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the first caller to be canceled, got %v", err)
+	}
+	select {
+	case <-prov.canceled:
+		t.Fatal("expected the provider to keep running for the second caller")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(prov.release)
+	if resp := <-second; resp.Cheapest == nil {
+		t.Fatalf("expected the second caller to get the result, got %+v", resp)
+	}
+	if n := prov.calls.Load(); n != 1 {
+		t.Fatalf("expected one provider call, got %d", n)
+	}
+}
+
+func TestCoalescedSearchCanceledWhenAllCallersLeave(t *testing.T) {
+	prov := newGatedProv()
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, noCache{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() { _, _ = svc.Search(ctx, coalescedReq) })
+	}
+	<-prov.started
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	select {
+	case <-prov.canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the provider to be canceled once every caller left")
+	}
+
+	// the next search starts afresh instead of joining the canceled one
+	done := make(chan error, 1)
+	go func() {
+		_, err := svc.Search(context.Background(), coalescedReq)
+		done <- err
+	}()
+	<-prov.started
+	close(prov.release)
+	if err := <-done; err != nil {
+		t.Fatalf("expected a fresh search to succeed, got %v", err)
+	}
+	if n := prov.calls.Load(); n != 2 {
+		t.Fatalf("expected two provider calls, got %d", n)
+	}
+}
+
+func TestCanceledCoalescedSearchIsNotCached(t *testing.T) {
+	gated := newGatedProv()
+	fast := fakeProv{name: "fast", qs: []domain.Quote{{Provider: "fast", Price: domain.NewMoney(90000, "USD")}}}
+	c := flights.NewInMemoryTTL()
+	svc := flights.NewService([]providers.Provider{fast, gated}, 5*time.Second, c)
+	store := history.NewMemoryStore()
+	svc.SetObservationStore(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Go(func() { _, _ = svc.Search(ctx, coalescedReq) })
+	}
+	<-gated.started
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	wg.Wait()
+	<-gated.canceled
+	time.Sleep(50 * time.Millisecond) // let the canceled fan-out finish
+
+	if n := c.Len(); n != 0 {
+		t.Fatalf("expected the canceled response not to be cached, got %d entries", n)
+	}
+	if obs, _ := store.Query(context.Background(), history.Filter{}); len(obs) != 0 {
+		t.Fatalf("expected the canceled response not to be recorded, got %+v", obs)
+	}
+
+	done := make(chan domain.AggregatedResponse, 1)
+	go func() {
+		resp, _ := svc.Search(context.Background(), coalescedReq)
+		done <- resp
+	}()
+	<-gated.started
+	close(gated.release)
+	resp := <-done
+	if n := gated.calls.Load(); n != 2 {
+		t.Fatalf("expected a fresh fan-out, got %d calls", n)
+	}
+	if resp.Partial || resp.Cheapest == nil || resp.Cheapest.Provider != "gated" {
+		t.Fatalf("expected a complete response, got %+v", resp)
+	}
+}
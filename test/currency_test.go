@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+var testRates = fx.Table{Base: "USD", Rates: map[string]float64{"EUR": 0.5, "BRL": 5}}
+
+func TestFXFileSource(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base":"USD","rates":{"EUR":0.5,"BRL":5}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := fx.NewFileSource(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fx.Convert(context.Background(), src, domain.NewMoney(10000, "EUR"), "BRL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != domain.NewMoney(100000, "BRL") {
+		t.Fatalf("expected 100 EUR = 1000 BRL, got %v", got)
+	}
+	if _, err := src.Rate(context.Background(), "USD", "XXX"); err == nil {
+		t.Fatal("expected unknown currency error")
+	}
+}
+
+func TestSearchConvertsBeforeRanking(t *testing.T) {
+	stub := httptest.NewServer(fx.StubHandler(testRates))
+	defer stub.Close()
+
+	// 300 EUR is 600 USD, so the 500 USD offer is the cheapest despite the smaller number
+	eur := fakeProv{name: "eur", qs: []domain.Quote{{Provider: "eur", Price: domain.NewMoney(30000, "EUR")}}}
+	usd := fakeProv{name: "usd", qs: []domain.Quote{{Provider: "usd", Price: domain.NewMoney(50000, "USD")}}}
+
+	svc := flights.NewService([]providers.Provider{eur, usd}, 5*time.Second, flights.NewInMemoryTTL())
+	svc.SetRateSource(fx.NewHTTPSource(stub.Client(), stub.URL, time.Minute))
+
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Currency: "usd",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Cheapest.Provider != "usd" {
+		t.Fatalf("expected the USD offer to be cheapest, got %+v", resp.Cheapest)
+	}
+	converted := resp.Offers[1]
+	if converted.Price != domain.NewMoney(60000, "USD") || converted.OriginalPrice == nil || *converted.OriginalPrice != domain.NewMoney(30000, "EUR") {
+		t.Fatalf("unexpected converted quote %+v", converted)
+	}
+}
+
+func TestSearchWithoutRatesDropsOtherCurrencies(t *testing.T) {
+	// without a rate source 300 EUR can't be ranked against 500 USD
+	eur := fakeProv{name: "eur", qs: []domain.Quote{{Provider: "eur", Price: domain.NewMoney(30000, "EUR")}}}
+	usd := fakeProv{name: "usd", qs: []domain.Quote{{Provider: "usd", Price: domain.NewMoney(50000, "USD")}}}
+	svc := flights.NewService([]providers.Provider{eur, usd}, 5*time.Second, noCache{})
+
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Offers) != 1 || resp.Cheapest.Provider != "usd" {
+		t.Fatalf("expected only the USD offer, got %+v", resp.Offers)
+	}
+	if !resp.Partial || !slices.ContainsFunc(resp.Providers, func(st domain.ProviderStatus) bool {
+		return st.Name == "eur" && st.Status == domain.ProviderError
+	}) {
+		t.Fatalf("expected the unconverted provider to be reported, got %+v", resp.Providers)
+	}
+}
+
+func TestFXHTTPSourceSharesRefreshesAndBacksOff(t *testing.T) {
+	var hits atomic.Int64
+	failing := atomic.Bool{}
+	failing.Store(true)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if failing.Load() {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		fx.StubHandler(testRates).ServeHTTP(w, r)
+	}))
+	defer stub.Close()
+	src := fx.NewHTTPSource(stub.Client(), stub.URL, time.Minute)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := src.Rate(context.Background(), "USD", "EUR"); err == nil {
+				t.Error("expected an error while the rates API is down")
+			}
+		})
+	}
+	wg.Wait()
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("expected concurrent callers to share one refresh, got %d", n)
+	}
+
+	// the failure is remembered instead of retried on every call
+	failing.Store(false)
+	if _, err := src.Rate(context.Background(), "USD", "EUR"); err == nil || hits.Load() != 1 {
+		t.Fatalf("expected no retry right after a failure, got %v after %d calls", err, hits.Load())
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if r, err := src.Rate(context.Background(), "USD", "EUR"); err != nil || r != 0.5 {
+		t.Fatalf("expected the retry to succeed, got %v %v", r, err)
+	}
+}
+
+func TestFXHTTPSourceRejectsEmptyTables(t *testing.T) {
+	for _, body := range []string{`{"base":"","rates":{"EUR":0.5}}`, `{"base":"USD","rates":{}}`, `{"error":"quota exceeded"}`} {
+		stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		src := fx.NewHTTPSource(stub.Client(), stub.URL, time.Minute)
+		if _, err := src.Rate(context.Background(), "USD", "USD"); err == nil {
+			t.Fatalf("expected %s to be rejected", body)
+		}
+		stub.Close()
+	}
+}
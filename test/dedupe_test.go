@@ -0,0 +1,102 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func leg(flight string, dep time.Time) *domain.Itinerary {
+	it := domain.NewItinerary([]domain.Segment{{
+		Carrier: flight[:2], FlightNumber: flight, Origin: "GRU", Destination: "JFK",
+		DepartureAt: dep, ArrivalAt: dep.Add(10 * time.Hour), Duration: 10 * time.Hour,
+	}})
+	return &it
+}
+
+func TestDedupeAcrossProviders(t *testing.T) {
+	dep := time.Date(2025, 12, 1, 22, 0, 0, 0, time.UTC)
+	back := time.Date(2025, 12, 10, 23, 0, 0, 0, time.UTC)
+
+	amadeus := fakeProv{name: "amadeus", qs: []domain.Quote{
+		{Provider: "amadeus", Price: domain.NewMoney(90000, "USD"), Outbound: leg("LA8180", dep), Inbound: leg("LA8181", back)},
+		{Provider: "amadeus", Price: domain.NewMoney(95000, "USD"), Outbound: leg("AA930", dep), Inbound: leg("AA929", back)},
+	}}
+	google := fakeProv{name: "google", qs: []domain.Quote{
+		{Provider: "google", Price: domain.NewMoney(93000, "USD"), Outbound: leg("AA930", dep), Inbound: leg("AA929", back)},
+		// only the outbound is described: the return flight may differ, so it stays apart
+		{Provider: "google", Price: domain.NewMoney(88000, "USD"), Outbound: leg("LA8180", dep)},
+		{Provider: "google", Price: domain.NewMoney(99000, "USD"), Outbound: leg("LA8180", dep.Add(time.Hour))},
+	}}
+
+	svc := flights.NewService([]providers.Provider{amadeus, google}, 5*time.Second, flights.NewInMemoryTTL())
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: dep, EndDate: back,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Offers) != 4 {
+		t.Fatalf("expected 4 distinct offers, got %d", len(resp.Offers))
+	}
+	var merged []domain.Quote
+	for _, o := range resp.Offers {
+		if len(o.Providers) > 0 {
+			merged = append(merged, o)
+		}
+	}
+	if len(merged) != 1 {
+		t.Fatalf("expected only the matching round trips to merge, got %+v", merged)
+	}
+	m := merged[0]
+	if m.Provider != "google" || m.Price != domain.NewMoney(93000, "USD") || m.Outbound.Segments[0].FlightNumber != "AA930" {
+		t.Fatalf("expected the cheapest provider as headline, got %s %v", m.Provider, m.Price)
+	}
+	if m.Inbound == nil || len(m.Providers) != 2 {
+		t.Fatalf("expected the inbound and both providers, got %+v", m)
+	}
+}
+
+func TestDedupeOutboundOnlyRoundTrips(t *testing.T) {
+	dep := time.Date(2026, 12, 1, 22, 0, 0, 0, time.UTC)
+	back := time.Date(2026, 12, 10, 23, 0, 0, 0, time.UTC)
+
+	// Amadeus describes both legs; Google Flights and the second outbound-only
+	// provider price the round trip from its outbound alone
+	amadeus := fakeProv{name: "amadeus", qs: []domain.Quote{
+		{Provider: "amadeus", Price: domain.NewMoney(90000, "USD"), Outbound: leg("LA8180", dep), Inbound: leg("LA8181", back)},
+	}}
+	google := fakeProv{name: "google", qs: []domain.Quote{
+		{Provider: "google", Price: domain.NewMoney(88000, "USD"), Outbound: leg("LA8180", dep)},
+	}}
+	other := fakeProv{name: "other", qs: []domain.Quote{
+		{Provider: "other", Price: domain.NewMoney(87000, "USD"), Outbound: leg("LA8180", dep)},
+	}}
+
+	svc := flights.NewService([]providers.Provider{amadeus, google, other}, 5*time.Second, noCache{})
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: dep, EndDate: back,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Offers) != 2 {
+		t.Fatalf("expected the full round trip and one merged outbound-only offer, got %+v", resp.Offers)
+	}
+	for _, o := range resp.Offers {
+		switch {
+		case o.Inbound != nil:
+			if o.Provider != "amadeus" || len(o.Providers) != 0 {
+				t.Fatalf("expected the described round trip to stay apart, got %+v", o)
+			}
+		case len(o.Providers) != 2 || o.Provider != "other":
+			t.Fatalf("expected the outbound-only offers to merge, got %+v", o)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/fx"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+const amadeusFlightDatesFixture = `{"meta":{"currency":"EUR"},"data":[
+	{"type":"flight-date","origin":"GRU","destination":"NRT","departureDate":"2026-04-14","returnDate":"2026-04-25","price":{"total":"1320.00"}},
+	{"type":"flight-date","origin":"GRU","destination":"NRT","departureDate":"2026-03-02","returnDate":"2026-03-13","price":{"total":"1190.50"}}]}`
+
+func TestAmadeusCheapestDates(t *testing.T) {
+	var query map[string]string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 1799})
+	})
+	mux.HandleFunc("/v1/shopping/flight-dates", func(w http.ResponseWriter, r *http.Request) {
+		query = map[string]string{}
+		for k := range r.URL.Query() {
+			query[k] = r.URL.Query().Get(k)
+		}
+		_, _ = w.Write([]byte(amadeusFlightDatesFixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	prices, err := a.CheapestDates(context.Background(), domain.ExploreRequest{
+		Origin: "GRU", Destination: "NRT",
+		From:    time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:      time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+		MinDays: 10, MaxDays: 14,
+	}.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query["departureDate"] != "2026-03-01,2026-06-30" || query["duration"] != "10,14" || query["oneWay"] != "false" {
+		t.Fatalf("unexpected query %v", query)
+	}
+	if len(prices) != 2 || prices[1].Price != domain.NewMoney(119050, "EUR") || prices[1].Source != domain.SourceLive {
+		t.Fatalf("unexpected prices %+v", prices)
+	}
+}
+
+func TestExploreUsesCheapestDateProvider(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "token", "expires_in": 1799})
+	})
+	mux.HandleFunc("/v1/shopping/flight-dates", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(amadeusFlightDatesFixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	sampled := &datePricedProv{}
+	amadeus := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	svc := flights.NewService([]providers.Provider{amadeus, sampled}, 5*time.Second, noCache{})
+	rates := httptest.NewServer(fx.StubHandler(testRates))
+	defer rates.Close()
+	svc.SetRateSource(fx.NewHTTPSource(rates.Client(), rates.URL, time.Minute))
+
+	resp, err := svc.Explore(context.Background(), domain.ExploreRequest{
+		Origin: "GRU", Destination: "NRT",
+		From: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampled.calls.Load() != 0 {
+		t.Fatalf("expected no sampled searches, got %d", sampled.calls.Load())
+	}
+	if len(resp.Results) != 2 || resp.Results[0].DepartureDate != "2026-03-02" || resp.Results[0].Price != domain.NewMoney(238100, "USD") {
+		t.Fatalf("unexpected results %+v", resp.Results)
+	}
+}
+
+func TestExploreFallsBackToSamplesAndHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := &datePricedProv{}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, noCache{})
+	store := history.NewMemoryStore()
+	svc.SetObservationStore(store)
+
+	day := func(d int) time.Time { return time.Date(2025, 12, d, 0, 0, 0, 0, time.UTC) }
+	seen := func(dep, ret time.Time, minor int64) domain.Observation {
+		return domain.Observation{
+			Origin: "GRU", Destination: "JFK", Provider: "p", Airline: "LA",
+			DepartureDate: dep, ReturnDate: ret,
+			Price: domain.NewMoney(minor, "USD"), ObservedAt: time.Now(),
+		}
+	}
+	_ = store.Record(context.Background(), []domain.Observation{
+		seen(day(5), day(15), 40000),                // 10 days: a historical estimate
+		seen(day(5), day(25), 10000),                // 20 days: trip too long
+		seen(day(2), day(11), 1000),                 // superseded by the live quote
+		seen(day(3), time.Time{}, 1000),             // one-way
+		seen(day(1).AddDate(0, 1, 0), day(9), 1000), // outside the window
+	})
+
+	engine := httpserver.New(svc, "secret").Engine()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/flights/explore?origin=GRU&destination=JFK&from=2025-12-01&to=2025-12-12&minDays=7&maxDays=11&limit=3", nil)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	engine.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var resp domain.ExploreResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if n := prov.calls.Load(); n != 12 {
+		t.Fatalf("expected 12 sampled searches, got %d", n)
+	}
+	if len(resp.Results) != 3 {
+		t.Fatalf("expected 3 results, got %+v", resp.Results)
+	}
+	first, second := resp.Results[0], resp.Results[1]
+	if first.DepartureDate != "2025-12-05" || first.ReturnDate != "2025-12-15" || first.Source != domain.SourceHistorical {
+		t.Fatalf("expected the historical estimate first, got %+v", first)
+	}
+	if second.DepartureDate != "2025-12-02" || second.ReturnDate != "2025-12-11" || second.Source != domain.SourceLive || second.Price != domain.NewMoney(50000, "USD") {
+		t.Fatalf("expected the cheapest live quote second, got %+v", second)
+	}
+}
+
+func TestExploreRejectsLongWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{&datePricedProv{}}, time.Second, noCache{})
+	engine := httpserver.New(svc, "secret").Engine()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/flights/explore?origin=GRU&destination=JFK&from=2025-01-01&to=2026-06-01", nil)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,233 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/history"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func observed(origin, dest string, minor int64, at time.Time) domain.Observation {
+	return domain.Observation{
+		Origin: origin, Destination: dest, Provider: "p", Airline: "LA",
+		DepartureDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Price:         domain.NewMoney(minor, "USD"), ObservedAt: at,
+	}
+}
+
+func TestMonthlyAggregates(t *testing.T) {
+	oct := time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)
+	nov := time.Date(2025, 11, 5, 0, 0, 0, 0, time.UTC)
+	months := history.Monthly([]domain.Observation{
+		observed("GRU", "JFK", 90000, nov),
+		observed("GRU", "JFK", 70000, oct),
+		observed("GRU", "JFK", 80000, oct),
+		observed("GRU", "JFK", 100000, oct),
+		observed("GRU", "JFK", 60000, oct),
+	}, history.ByObservation)
+
+	if len(months) != 2 || months[0].Month != "2025-10" || months[1].Month != "2025-11" {
+		t.Fatalf("unexpected months %+v", months)
+	}
+	m := months[0]
+	if m.Count != 4 || m.Min.Minor != 60000 || m.Median.Minor != 75000 || m.Average.Minor != 77500 {
+		t.Fatalf("unexpected October aggregate %+v", m)
+	}
+}
+
+func TestMemoryStoreQueriesByRoute(t *testing.T) {
+	at := time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)
+	store := history.NewMemoryStore()
+	_ = store.Record(context.Background(), []domain.Observation{
+		observed("GRU", "JFK", 50000, at),
+		observed("GRU", "LIS", 60000, at),
+		observed("CGH", "JFK", 70000, at),
+		observed("CGH", "LIS", 80000, at),
+	})
+
+	for name, tc := range map[string]struct {
+		f    history.Filter
+		want int
+	}{
+		"both ends":        {history.Filter{Origins: []string{"GRU", "CGH"}, Destinations: []string{"JFK"}}, 2},
+		"origin only":      {history.Filter{Origins: []string{"GRU"}}, 2},
+		"destination only": {history.Filter{Destinations: []string{"LIS"}}, 2},
+		"unknown route":    {history.Filter{Origins: []string{"GRU"}, Destinations: []string{"MIA"}}, 0},
+	} {
+		obs, err := store.Query(context.Background(), tc.f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(obs) != tc.want {
+			t.Fatalf("%s: expected %d observations, got %+v", name, tc.want, obs)
+		}
+	}
+}
+
+func TestFileStoreSurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	st, err := history.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2025, 10, 5, 0, 0, 0, 0, time.UTC)
+	if err := st.Record(context.Background(), []domain.Observation{observed("GRU", "JFK", 50000, at), observed("GRU", "LIS", 40000, at)}); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	reopened, err := history.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	got, err := reopened.Query(context.Background(), history.Filter{Origins: []string{"GRU"}, Destinations: []string{"JFK"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Price != domain.NewMoney(50000, "USD") || !got[0].ObservedAt.Equal(at) {
+		t.Fatalf("unexpected observations after reopen %+v", got)
+	}
+}
+
+func TestFileStoreDropsExpiredObservations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	now := time.Now().UTC()
+	old, recent := now.AddDate(-3, 0, 0), now.AddDate(0, -1, 0)
+
+	st, err := history.OpenFileStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := st.Record(context.Background(), []domain.Observation{observed("GRU", "JFK", 50000, old), observed("GRU", "JFK", 40000, recent)}); err != nil {
+		t.Fatal(err)
+	}
+	st.Close()
+
+	year := 365 * 24 * time.Hour
+	reopened, err := history.OpenFileStore(path, year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	got, _ := reopened.Query(context.Background(), history.Filter{})
+	if len(got) != 1 || !got[0].ObservedAt.Equal(recent) {
+		t.Fatalf("expected only the recent observation, got %+v", got)
+	}
+	if raw, _ := os.ReadFile(path); strings.Count(string(raw), "\n") != 1 {
+		t.Fatalf("expected the file to be rewritten without the old line, got %s", raw)
+	}
+
+	// observations recorded since still reach the file, and Compact drops them once expired
+	if err := reopened.Record(context.Background(), []domain.Observation{observed("GRU", "LIS", 30000, old)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.Query(context.Background(), history.Filter{}); len(got) != 1 {
+		t.Fatalf("expected the expired observation to be compacted, got %+v", got)
+	}
+	if raw, _ := os.ReadFile(path); strings.Count(string(raw), "\n") != 1 {
+		t.Fatalf("expected one line after compaction, got %s", raw)
+	}
+}
+
+func TestSearchRecordsHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := fakeProv{name: "p", qs: []domain.Quote{
+		{Provider: "p", Airline: "LA", Origin: "JFK", Destination: "GRU", Price: domain.NewMoney(50000, "USD")},
+		{Provider: "p", Airline: "AA", Origin: "JFK", Destination: "GRU", Price: domain.NewMoney(70000, "USD")},
+	}}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, flights.NewInMemoryTTL())
+	svc.SetObservationStore(history.NewMemoryStore())
+
+	if _, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "JFK", Destination: "GRU", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := httpserver.New(svc, "secret").Engine()
+	get := func(query string) string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/flights/history?origin=NYC&destination=GRU"+query, nil)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		engine.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		return w.Body.String()
+	}
+
+	// grouped by the departure month by default
+	want := `{"month":"2025-12","minPrice":500.00,"medianPrice":600.00,"avgPrice":600.00,"count":2,"currency":"USD"}`
+	if body := get("&from=2025-06"); !strings.Contains(body, want) || !strings.Contains(body, `"groupBy":"departure"`) {
+		t.Fatalf("expected %s in %s", want, body)
+	}
+	month := time.Now().UTC().Format("2006-01")
+	want = `{"month":"` + month + `","minPrice":500.00,"medianPrice":600.00,"avgPrice":600.00,"count":2,"currency":"USD"}`
+	if body := get("&groupBy=observed"); !strings.Contains(body, want) {
+		t.Fatalf("expected %s in %s", want, body)
+	}
+}
+
+func TestHistorySeparatesCabinsAndPassengers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := &datePricedProv{}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, flights.NewInMemoryTTL())
+	svc.SetObservationStore(history.NewMemoryStore())
+
+	dep := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	for _, r := range []domain.SearchRequest{
+		{Origin: "GRU", Destination: "JFK", StartDate: dep},
+		{Origin: "GRU", Destination: "JFK", StartDate: dep, Adults: 2, Cabin: domain.CabinBusiness},
+	} {
+		if _, err := svc.Search(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	engine := httpserver.New(svc, "secret").Engine()
+	count := func(query string) int {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/flights/history?origin=GRU&destination=JFK&from=2025-12"+query, nil)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		engine.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp struct {
+			History []struct{ Count int }
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		n := 0
+		for _, m := range resp.History {
+			n += m.Count
+		}
+		return n
+	}
+
+	if n := count(""); n != 1 {
+		t.Fatalf("expected only the economy single-traveler price by default, got %d", n)
+	}
+	if n := count("&cabin=business&passengers=2"); n != 1 {
+		t.Fatalf("expected the business price for two, got %d", n)
+	}
+	if n := count("&passengers=2"); n != 0 {
+		t.Fatalf("expected no economy price for two, got %d", n)
+	}
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/watch"
+)
+
+// countingSearcher counts searches and returns a fixed offer
+type countingSearcher struct{ calls atomic.Int64 }
+
+func (s *countingSearcher) Search(ctx context.Context, req domain.SearchRequest) (domain.AggregatedResponse, error) {
+	s.calls.Add(1)
+	q := domain.Quote{Provider: "p", Price: domain.NewMoney(50000, "USD")}
+	return domain.AggregatedResponse{Cheapest: &q, Fastest: &q, Offers: []domain.Quote{q}}, nil
+}
+
+func TestHubSharesOnePollerPerRoute(t *testing.T) {
+	searcher := &countingSearcher{}
+	hub := watch.NewHub(searcher)
+	req := domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+	first := hub.Subscribe(req, time.Hour)
+	<-first.C
+	// same route in lowercase joins the running poller and gets its latest update
+	second := hub.Subscribe(domain.SearchRequest{Origin: "gru", Destination: "jfk", StartDate: req.StartDate}, time.Hour)
+	select {
+	case <-second.C:
+	case <-time.After(time.Second):
+		t.Fatal("expected the latest update on subscribe")
+	}
+
+	stats := hub.Stats()
+	if len(stats) != 1 || stats[0].Subscribers != 2 || stats[0].Route != "GRU|JFK|2025-12-01" {
+		t.Fatalf("expected one poller with 2 subscribers, got %+v", stats)
+	}
+	if n := searcher.calls.Load(); n != 1 {
+		t.Fatalf("expected a single search for both subscribers, got %d", n)
+	}
+
+	first.Close()
+	if stats := hub.Stats(); len(stats) != 1 || stats[0].Subscribers != 1 {
+		t.Fatalf("expected the poller to keep running for the remaining subscriber, got %+v", stats)
+	}
+	second.Close()
+	if stats := hub.Stats(); len(stats) != 0 {
+		t.Fatalf("expected the poller to stop with its last subscriber, got %+v", stats)
+	}
+	if _, open := <-second.C; open {
+		t.Fatal("expected the subscription channel to be closed")
+	}
+}
+
+func TestHubPollsAtTheShortestSubscriberInterval(t *testing.T) {
+	searcher := &countingSearcher{}
+	hub := watch.NewHub(searcher)
+	req := domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+	slow := hub.Subscribe(req, time.Hour)
+	defer slow.Close()
+	<-slow.C
+	fast := hub.Subscribe(req, 20*time.Millisecond)
+
+	stats := hub.Stats()
+	if len(stats) != 1 || stats[0].Subscribers != 2 || stats[0].Interval != "20ms" {
+		t.Fatalf("expected one poller every 20ms, got %+v", stats)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n := searcher.calls.Load(); n < 3 {
+		t.Fatalf("expected the poller to speed up for the faster subscriber, got %d searches", n)
+	}
+
+	fast.Close()
+	if stats := hub.Stats(); len(stats) != 1 || stats[0].Interval != "1h0m0s" {
+		t.Fatalf("expected the poller to slow down once the fast subscriber left, got %+v", stats)
+	}
+	time.Sleep(30 * time.Millisecond) // a poll already under way may still finish
+	before := searcher.calls.Load()
+	time.Sleep(100 * time.Millisecond)
+	if n := searcher.calls.Load(); n != before {
+		t.Fatalf("expected no more polls at the hourly interval, got %d more", n-before)
+	}
+}
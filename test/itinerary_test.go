@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+const amadeusConnectionFixture = `{"data":[{"itineraries":[
+	{"duration":"PT14H","segments":[
+		{"departure":{"iataCode":"GRU","terminal":"3","at":"2025-12-01T08:00:00"},
+		 "arrival":{"iataCode":"LIM","at":"2025-12-01T11:00:00"},
+		 "carrierCode":"LA","number":"2455","aircraft":{"code":"320"},"operating":{"carrierCode":"LA"}},
+		{"departure":{"iataCode":"LIM","at":"2025-12-01T13:00:00"},
+		 "arrival":{"iataCode":"JFK","terminal":"8","at":"2025-12-01T21:00:00"},
+		 "carrierCode":"LA","number":"2480","aircraft":{"code":"788"},"operating":{"carrierCode":"AA"}}]},
+	{"duration":"PT10H","segments":[
+		{"departure":{"iataCode":"JFK","at":"2025-12-10T22:00:00"},
+		 "arrival":{"iataCode":"GRU","at":"2025-12-11T09:00:00"},
+		 "carrierCode":"LA","number":"8181","aircraft":{"code":"77W"},"numberOfStops":0}]}],
+	"price":{"currency":"USD","grandTotal":"980.10"}}]}`
+
+func TestAmadeusPopulatesSegments(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"t","expires_in":1799}`))
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(amadeusConnectionFixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	qs, err := a.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := qs[0]
+	if q.Outbound == nil || q.Inbound == nil {
+		t.Fatalf("expected outbound and inbound legs, got %+v", q)
+	}
+	if q.Outbound.Stops != 1 || len(q.Outbound.Segments) != 2 {
+		t.Fatalf("expected 1 stop over 2 segments, got %+v", q.Outbound)
+	}
+	seg := q.Outbound.Segments[1]
+	if seg.FlightNumber != "LA2480" || seg.OperatingCarrier != "AA" || seg.Aircraft != "788" || seg.ArrivalTerminal != "8" {
+		t.Fatalf("unexpected segment %+v", seg)
+	}
+	if q.Inbound.Stops != 0 || q.Inbound.Origin != "JFK" {
+		t.Fatalf("unexpected inbound %+v", q.Inbound)
+	}
+	if q.Stops() != 1 {
+		t.Fatalf("expected quote stops 1, got %d", q.Stops())
+	}
+}
+
+func TestGoogleFlightsPopulatesSegments(t *testing.T) {
+	fixture := `{"best_flights":[{"flights":[
+		{"departure_airport":{"id":"GRU","time":"2025-12-01 08:00"},"arrival_airport":{"id":"PTY","time":"2025-12-01 13:00"},
+		 "airline":"Copa","flight_number":"CM 702","airplane":"Boeing 737"},
+		{"departure_airport":{"id":"PTY","time":"2025-12-01 15:00"},"arrival_airport":{"id":"JFK","time":"2025-12-01 21:00"},
+		 "airline":"Copa","flight_number":"CM 830","airplane":"Boeing 737"}],"price":650}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fixture))
+	}))
+	defer srv.Close()
+
+	g := providers.NewGoogleFlights(srv.Client(), srv.URL, "key")
+	qs, err := g.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := qs[0].Outbound
+	if out == nil || out.Stops != 1 || out.Segments[0].FlightNumber != "CM702" || out.Segments[0].Carrier != "CM" {
+		t.Fatalf("unexpected outbound %+v", out)
+	}
+}
+
+func TestNonStopFilterDropsConnections(t *testing.T) {
+	now := time.Now()
+	seg := func(from, to string, dep time.Time) domain.Segment {
+		return domain.Segment{Origin: from, Destination: to, DepartureAt: dep, ArrivalAt: dep.Add(2 * time.Hour)}
+	}
+	direct := domain.NewItinerary([]domain.Segment{seg("GRU", "JFK", now)})
+	connecting := domain.NewItinerary([]domain.Segment{seg("GRU", "PTY", now), seg("PTY", "JFK", now.Add(3*time.Hour))})
+
+	p := fakeProv{name: "p", qs: []domain.Quote{
+		{Provider: "p", Price: domain.NewMoney(10000, "USD"), Outbound: &connecting},
+		{Provider: "p", Price: domain.NewMoney(20000, "USD"), Outbound: &direct},
+	}}
+	svc := flights.NewService([]providers.Provider{p}, 5*time.Second, flights.NewInMemoryTTL())
+
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: now, NonStop: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Offers) != 1 || resp.Cheapest.Price.Minor != 20000 {
+		t.Fatalf("expected only the direct flight, got %+v", resp.Offers)
+	}
+}
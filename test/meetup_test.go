@@ -0,0 +1,157 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// fare is the price in USD and the travel time in hours of a route
+type fare struct{ usd, hours int64 }
+
+// routePricedProv prices routes from a table and counts the searches per route
+type routePricedProv struct {
+	fares map[string]fare // keyed by "GRU-LIS"
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *routePricedProv) Name() string { return "routes" }
+func (p *routePricedProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	key := q.Origin + "-" + q.Destination
+	p.mu.Lock()
+	p.calls[key]++
+	p.mu.Unlock()
+
+	f, ok := p.fares[key]
+	if !ok {
+		return nil, errors.New("no flights")
+	}
+	return []domain.Quote{{
+		Provider: "routes", Origin: q.Origin, Destination: q.Destination,
+		Price: domain.NewMoney(f.usd*100, "USD"), Duration: time.Duration(f.hours) * time.Hour,
+	}}, nil
+}
+
+func TestMeetupRanking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	prov := &routePricedProv{calls: map[string]int{}, fares: map[string]fare{
+		"GRU-LIS": {700, 10}, "JFK-LIS": {500, 7}, "LHR-LIS": {100, 2}, // 1800 USD, 10h
+		"GRU-MIA": {600, 8}, "JFK-MIA": {200, 3}, "LHR-MIA": {700, 9}, // 1700 USD, 9h
+		"GRU-LHR": {800, 11}, "JFK-LHR": {400, 7}, // 1600 USD, 11h
+		"JFK-NRT": {900, 14}, "LHR-NRT": {900, 12}, // nothing from GRU
+	}}
+	svc := flights.NewService([]providers.Provider{prov}, 5*time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	meetup := func(rankBy string) domain.MeetupResponse {
+		body, _ := json.Marshal(map[string]any{
+			"origins":       []string{"GRU", "JFK", "JFK", "LHR"},
+			"destinations":  []string{"LIS", "NRT", "MIA", "LHR"},
+			"departureDate": "2025-12-01",
+			"rankBy":        rankBy,
+		})
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/flights/meetup", bytes.NewReader(body))
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		if w.Code != 200 {
+			t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp domain.MeetupResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+	order := func(resp domain.MeetupResponse) []string {
+		var out []string
+		for _, o := range resp.Destinations {
+			out = append(out, o.Destination)
+		}
+		return out
+	}
+
+	byPrice := meetup("price")
+	if got := order(byPrice); len(got) != 4 || got[0] != "LHR" || got[1] != "MIA" || got[2] != "LIS" || got[3] != "NRT" {
+		t.Fatalf("unexpected ranking by price %v", got)
+	}
+	lhr := byPrice.Destinations[0]
+	if !lhr.Complete || lhr.TotalPrice == nil || *lhr.TotalPrice != domain.NewMoney(160000, "USD") || lhr.MaxDuration != 11*time.Hour {
+		t.Fatalf("unexpected LHR option %+v", lhr)
+	}
+	if len(lhr.Attendees) != 4 || !lhr.Attendees[3].Local || lhr.Attendees[1].Quote == nil || lhr.City != "London" {
+		t.Fatalf("unexpected LHR attendees %+v", lhr.Attendees)
+	}
+	nrt := byPrice.Destinations[3]
+	if nrt.Complete || nrt.TotalPrice != nil || nrt.Attendees[0].Error == "" {
+		t.Fatalf("expected NRT to be incomplete, got %+v", nrt)
+	}
+	for route, n := range prov.calls {
+		if n != 1 {
+			t.Fatalf("expected %s to be searched once, got %d", route, n)
+		}
+	}
+	if _, ok := prov.calls["LHR-LHR"]; ok {
+		t.Fatal("expected no search for the local attendee")
+	}
+
+	if got := order(meetup("duration")); got[0] != "MIA" || got[1] != "LIS" || got[2] != "LHR" || got[3] != "NRT" {
+		t.Fatalf("unexpected ranking by duration %v", got)
+	}
+}
+
+func TestMeetupValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{providers.NewMockProvider("Mock")}, time.Second, flights.NewInMemoryTTL())
+	engine := httpserver.New(svc, "secret").Engine()
+
+	for name, body := range map[string]string{
+		"one origin":     `{"origins":["GRU"],"destinations":["LIS"],"departureDate":"2025-12-01"}`,
+		"unknown origin": `{"origins":["GRU","XYZ"],"destinations":["LIS"],"departureDate":"2025-12-01"}`,
+		"return before":  `{"origins":["GRU","JFK"],"destinations":["LIS"],"departureDate":"2025-12-01","returnDate":"2025-11-01"}`,
+		"too many":       `{"origins":["LON","NYC"],"destinations":["PAR","TYO","CHI","WAS"],"departureDate":"2026-12-01"}`,
+	} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/flights/meetup", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		req.Header.Set("Content-Type", "application/json")
+		engine.ServeHTTP(w, req)
+		if w.Code != 400 {
+			t.Fatalf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestMeetupRejectsTooManySearches(t *testing.T) {
+	calls := &atomic.Int32{}
+	svc := flights.NewService([]providers.Provider{countingProv{calls: calls}}, time.Second, noCache{})
+
+	// LON and NYC have 8 airports between them, so each destination city of
+	// 2-3 airports is 16-24 searches, 80 in all
+	_, err := svc.Meetup(context.Background(), domain.MeetupQuery{
+		Origins:       []string{"LON", "NYC"},
+		Destinations:  []string{"PAR", "TYO", "CHI", "WAS"},
+		DepartureDate: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+		Currency:      "USD",
+	})
+	if !errors.Is(err, flights.ErrSearchTooLarge) {
+		t.Fatalf("expected ErrSearchTooLarge, got %v", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatalf("expected no provider call, got %d", calls.Load())
+	}
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// pairRecorder records which airport pairs it was asked to search
+type pairRecorder struct {
+	mu    sync.Mutex
+	pairs []string
+}
+
+func (r *pairRecorder) Name() string { return "recorder" }
+func (r *pairRecorder) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	r.mu.Lock()
+	r.pairs = append(r.pairs, q.Origin+"-"+q.Destination)
+	r.mu.Unlock()
+	return []domain.Quote{{Provider: "recorder", Price: domain.NewMoney(10000, "USD"), Origin: q.Origin, Destination: q.Destination}}, nil
+}
+
+func TestMetroAreaExpansion(t *testing.T) {
+	rec := &pairRecorder{}
+	svc := flights.NewService([]providers.Provider{rec}, 5*time.Second, flights.NewInMemoryTTL())
+
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "NYC", Destination: "LIS", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(rec.pairs)
+	want := []string{"EWR-LIS", "JFK-LIS", "LGA-LIS"}
+	if len(rec.pairs) != len(want) {
+		t.Fatalf("expected pairs %v, got %v", want, rec.pairs)
+	}
+	for i := range want {
+		if rec.pairs[i] != want[i] {
+			t.Fatalf("expected pairs %v, got %v", want, rec.pairs)
+		}
+	}
+
+	origins := map[string]bool{}
+	for _, q := range resp.Offers {
+		origins[q.Origin] = true
+	}
+	if len(origins) != 3 {
+		t.Fatalf("expected quotes tagged with the 3 NYC airports, got %v", origins)
+	}
+}
+
+func TestNearbyAirportExpansion(t *testing.T) {
+	rec := &pairRecorder{}
+	svc := flights.NewService([]providers.Provider{rec}, 5*time.Second, flights.NewInMemoryTTL())
+
+	_, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "LIS", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), NearbyKm: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(rec.pairs)
+	if len(rec.pairs) != 3 || rec.pairs[0] != "CGH-LIS" || rec.pairs[2] != "VCP-LIS" {
+		t.Fatalf("expected GRU, CGH and VCP origins, got %v", rec.pairs)
+	}
+}
+
+func TestAirportPairLimitKeepsClosestAndReportsSkipped(t *testing.T) {
+	// Paris plus everything within 400km, from Lille to Geneva, times the
+	// 3 NYC airports is 63 pairs
+	req := domain.SearchRequest{Origin: "PAR", Destination: "NYC", StartDate: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), NearbyKm: 400}
+
+	var runs [2][]string
+	for i := range runs {
+		rec := &pairRecorder{}
+		svc := flights.NewService([]providers.Provider{rec}, 5*time.Second, noCache{})
+		resp, err := svc.Search(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.SkippedPairs != 43 || len(rec.pairs) != 20 {
+			t.Fatalf("expected 20 pairs searched and 43 skipped, got %d and %d", len(rec.pairs), resp.SkippedPairs)
+		}
+		sort.Strings(rec.pairs)
+		runs[i] = rec.pairs
+	}
+	if strings.Join(runs[0], ",") != strings.Join(runs[1], ",") {
+		t.Fatalf("expected the same pairs every time, got %v and %v", runs[0], runs[1])
+	}
+
+	searched := strings.Join(runs[0], ",")
+	for _, o := range []string{"CDG", "ORY", "BVA", "LIL", "CRL", "BRU"} {
+		if strings.Count(searched, o+"-") != 3 {
+			t.Fatalf("expected every %s pair to be kept, got %v", o, runs[0])
+		}
+	}
+	for _, o := range []string{"GVA", "LYS", "AMS", "DUS"} {
+		if strings.Contains(searched, o+"-") {
+			t.Fatalf("expected the farthest airports to be skipped, got %v", runs[0])
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := []struct {
+		amount, currency string
+		minor            int64
+		ok               bool
+	}{
+		{"812.40", "USD", 81240, true},
+		{"812.4", "usd", 81240, true},
+		{"907", "USD", 90700, true},
+		{"15000", "JPY", 15000, true},
+		{"1.005", "USD", 0, false},
+		{"", "USD", 0, false},
+		{"abc", "USD", 0, false},
+		{"-10.00", "USD", 0, false},
+		{"1e3", "USD", 0, false},
+		{"10.00", "", 0, false},
+	}
+	for _, c := range cases {
+		m, err := domain.ParseMoney(c.amount, c.currency)
+		if (err == nil) != c.ok {
+			t.Errorf("ParseMoney(%q, %q): unexpected error %v", c.amount, c.currency, err)
+			continue
+		}
+		if c.ok && m.Minor != c.minor {
+			t.Errorf("ParseMoney(%q, %q) = %d, want %d", c.amount, c.currency, m.Minor, c.minor)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	fare := domain.NewMoney(10000, "USD")
+	fee := domain.NewMoney(1550, "USD")
+
+	total, err := fare.Add(fee)
+	if err != nil || total.String() != "115.50" {
+		t.Fatalf("expected 115.50, got %s %v", total, err)
+	}
+	if _, err := fare.Add(domain.NewMoney(1, "EUR")); err == nil {
+		t.Fatal("expected currency mismatch")
+	}
+	if got := fare.Markup(150).String(); got != "101.50" {
+		t.Fatalf("expected 1.5%% markup to give 101.50, got %s", got)
+	}
+	if got, err := domain.NewMoney(10000, "USD").Convert(150.1, "JPY"); err != nil || got != domain.NewMoney(15010, "JPY") {
+		t.Fatalf("expected 15010 JPY, got %+v %v", got, err)
+	}
+	for _, rate := range []float64{math.NaN(), math.Inf(1), 0, -1, 1e300} {
+		if got, err := domain.NewMoney(10000, "USD").Convert(rate, "EUR"); !errors.Is(err, domain.ErrInvalidRate) {
+			t.Fatalf("rate %v: expected ErrInvalidRate, got %+v %v", rate, got, err)
+		}
+	}
+}
+
+func TestQuoteJSONKeepsNumericPrice(t *testing.T) {
+	orig := domain.NewMoney(30000, "EUR")
+	q := domain.Quote{Provider: "Amadeus", Price: domain.NewMoney(81240, "USD"), OriginalPrice: &orig}
+
+	raw, err := json.Marshal(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"price":812.40`, `"currency":"USD"`, `"original_price":300.00`, `"original_currency":"EUR"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Fatalf("expected %s in %s", want, raw)
+		}
+	}
+
+	var back domain.Quote
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Price != q.Price || *back.OriginalPrice != orig {
+		t.Fatalf("round trip mismatch: %+v", back)
+	}
+}
+
+func TestAmadeusRejectsMalformedPrice(t *testing.T) {
+	fixture := strings.Replace(amadeusOffersFixture, `"812.40"`, `"N/A"`, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"t","expires_in":1799}`))
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	if qs, err := a.Search(context.Background(), richQuery); err == nil {
+		t.Fatalf("expected malformed price to be rejected, got %+v", qs)
+	}
+}
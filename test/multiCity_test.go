@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+var openJaw = domain.MultiCityRequest{
+	Legs: []domain.LegRequest{
+		{Origin: "GRU", Destination: "LIS", Date: "2025-12-01"},
+		{Origin: "LIS", Destination: "MAD", Date: "2025-12-05"},
+		{Origin: "MAD", Destination: "GRU", Date: "2025-12-10"},
+	},
+}
+
+func TestMultiCityRequestValidation(t *testing.T) {
+	bad := domain.MultiCityRequest{Legs: []domain.LegRequest{
+		{Origin: "GRU", Destination: "LIS", Date: "2025-12-05"},
+		{Origin: "LIS", Destination: "GRU", Date: "2025-12-01"},
+	}}
+	if _, err := bad.Query(); err == nil {
+		t.Fatal("expected error for legs out of order")
+	}
+
+	q, err := openJaw.Query()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Legs) != 3 || q.Adults != domain.DefaultAdults {
+		t.Fatalf("unexpected query %+v", q)
+	}
+}
+
+func TestSearchMultiCityUsesCapableProviders(t *testing.T) {
+	q, _ := openJaw.Query()
+
+	onlySimple := flights.NewService([]providers.Provider{fakeProv{name: "simple"}}, 5*time.Second, flights.NewInMemoryTTL())
+	if _, err := onlySimple.SearchMultiCity(context.Background(), q); !errors.Is(err, flights.ErrMultiCityUnsupported) {
+		t.Fatalf("expected ErrMultiCityUnsupported, got %v", err)
+	}
+
+	svc := flights.NewService([]providers.Provider{
+		fakeProv{name: "simple"},
+		providers.NewMockProvider("Mock"),
+	}, 5*time.Second, flights.NewInMemoryTTL())
+
+	resp, err := svc.SearchMultiCity(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Offers) == 0 || len(resp.Cheapest.Itineraries) != 3 {
+		t.Fatalf("expected quotes describing 3 legs, got %+v", resp.Cheapest)
+	}
+	if resp.Cheapest.Origin != "GRU" || resp.Cheapest.Itineraries[1].Origin != "LIS" {
+		t.Fatalf("unexpected legs %+v", resp.Cheapest.Itineraries)
+	}
+}
+
+func TestAmadeusMultiCityRequestBody(t *testing.T) {
+	var body struct {
+		OriginDestinations []struct {
+			OriginLocationCode string `json:"originLocationCode"`
+		} `json:"originDestinations"`
+		Travelers []struct {
+			TravelerType string `json:"travelerType"`
+		} `json:"travelers"`
+	}
+	fixture := `{"data":[{"itineraries":[
+		{"segments":[{"departure":{"iataCode":"GRU","at":"2025-12-01T22:00:00"},"arrival":{"iataCode":"LIS","at":"2025-12-02T11:00:00"},"carrierCode":"TP"}]},
+		{"segments":[{"departure":{"iataCode":"LIS","at":"2025-12-05T09:00:00"},"arrival":{"iataCode":"MAD","at":"2025-12-05T11:20:00"},"carrierCode":"IB"}]},
+		{"segments":[{"departure":{"iataCode":"MAD","at":"2025-12-10T23:55:00"},"arrival":{"iataCode":"GRU","at":"2025-12-11T07:00:00"},"carrierCode":"IB"}]}],
+		"price":{"currency":"USD","grandTotal":"1450.00"}}]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"t","expires_in":1799}`))
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(fixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	req := openJaw
+	req.Adults = 2
+	q, _ := req.Query()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	qs, err := a.SearchMultiCity(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(body.OriginDestinations) != 3 || body.OriginDestinations[2].OriginLocationCode != "MAD" {
+		t.Fatalf("unexpected originDestinations %+v", body.OriginDestinations)
+	}
+	if len(body.Travelers) != 2 {
+		t.Fatalf("expected 2 travelers, got %d", len(body.Travelers))
+	}
+	if len(qs) != 1 || len(qs[0].Itineraries) != 3 || qs[0].Destination != "GRU" {
+		t.Fatalf("unexpected quotes %+v", qs)
+	}
+}
+
+func TestTravelerCountsAreValidated(t *testing.T) {
+	lapInfants := openJaw
+	lapInfants.Adults, lapInfants.Infants = 1, 2
+	if _, err := lapInfants.Query(); !errors.Is(err, domain.ErrInvalidTravelers) {
+		t.Fatalf("expected more infants than adults to be rejected, got %v", err)
+	}
+	crowd := openJaw
+	crowd.Adults, crowd.Children = 6, 4
+	if _, err := crowd.Query(); !errors.Is(err, domain.ErrInvalidTravelers) {
+		t.Fatalf("expected ten seated travelers to be rejected, got %v", err)
+	}
+
+	prov := countingProv{calls: &atomic.Int32{}}
+	svc := flights.NewService([]providers.Provider{prov}, time.Second, noCache{})
+	engine := httpserver.New(svc, "secret").Engine()
+	for _, query := range []string{"adults=1&infants=2", "infants=1", "adults=5&children=5"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/flights/search?origin=GRU&destination=JFK&starDate=2026-12-01&"+query, nil)
+		req.Header.Set("Authorization", authHeader(t, "secret"))
+		engine.ServeHTTP(w, req)
+		if query == "infants=1" {
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: expected one infant with the default adult to pass, got %d %s", query, w.Code, w.Body)
+			}
+			continue
+		}
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d %s", query, w.Code, w.Body)
+		}
+	}
+	if n := prov.calls.Load(); n != 1 {
+		t.Fatalf("expected only the valid search to reach the provider, got %d calls", n)
+	}
+}
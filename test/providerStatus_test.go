@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// slowProv blocks until the search context is done
+type slowProv struct{ name string }
+
+func (s slowProv) Name() string { return s.name }
+func (s slowProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestSearchReportsProviderStatus(t *testing.T) {
+	ok := fakeProv{name: "ok", qs: []domain.Quote{{Provider: "ok", Price: domain.NewMoney(50000, "USD")}}}
+	failing := fakeProv{name: "failing", err: &providers.StatusError{Provider: "failing", Code: 500, Body: "secret=abc"}}
+	slow := slowProv{name: "slow"}
+
+	svc := flights.NewService([]providers.Provider{ok, failing, slow}, 200*time.Millisecond, flights.NewInMemoryTTL())
+	resp, err := svc.Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !resp.Partial {
+		t.Fatal("expected a partial response")
+	}
+	want := map[string]string{"ok": domain.ProviderOK, "failing": domain.ProviderError, "slow": domain.ProviderTimeout}
+	if len(resp.Providers) != len(want) {
+		t.Fatalf("expected %d provider statuses, got %+v", len(want), resp.Providers)
+	}
+	for _, st := range resp.Providers {
+		if st.Status != want[st.Name] {
+			t.Fatalf("expected %s to be %s, got %+v", st.Name, want[st.Name], st)
+		}
+		if strings.Contains(st.Error, "secret") {
+			t.Fatalf("provider error leaked the raw response: %q", st.Error)
+		}
+	}
+	if resp.Providers[0].Quotes != 1 {
+		t.Fatalf("expected 1 quote from ok, got %d", resp.Providers[0].Quotes)
+	}
+}
+
+func TestMultiCitySkipsIncapableProviders(t *testing.T) {
+	plain := fakeProv{name: "plain"}
+	mock := providers.NewMockProvider("Mock")
+
+	svc := flights.NewService([]providers.Provider{plain, mock}, 5*time.Second, flights.NewInMemoryTTL())
+	resp, err := svc.SearchMultiCity(context.Background(), domain.MultiCityQuery{Legs: []domain.Leg{
+		{Origin: "GRU", Destination: "LIS", Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)},
+		{Origin: "LIS", Destination: "GRU", Date: time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Partial {
+		t.Fatal("a skipped provider should not make the response partial")
+	}
+	if st := resp.Providers[0]; st.Status != domain.ProviderSkipped || st.Error == "" {
+		t.Fatalf("expected plain to be skipped, got %+v", st)
+	}
+}
+
+// ttlCache records the TTL of every stored response
+type ttlCache struct {
+	noCache
+	ttls []time.Duration
+}
+
+func (c *ttlCache) Set(_ string, _ domain.AggregatedResponse, ttl time.Duration) {
+	c.ttls = append(c.ttls, ttl)
+}
+
+func TestPartialResponsesCachedBriefly(t *testing.T) {
+	ok := fakeProv{name: "ok", qs: []domain.Quote{{Provider: "ok", Price: domain.NewMoney(50000, "USD")}}}
+	failing := fakeProv{name: "failing", err: &providers.StatusError{Provider: "failing", Code: 500}}
+	req := domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+	complete := &ttlCache{}
+	if _, err := flights.NewService([]providers.Provider{ok}, time.Second, complete).Search(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+	partial := &ttlCache{}
+	if _, err := flights.NewService([]providers.Provider{ok, failing}, time.Second, partial).Search(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(complete.ttls) != 1 || len(partial.ttls) != 1 {
+		t.Fatalf("expected one response cached each time, got %v and %v", complete.ttls, partial.ttls)
+	}
+	if partial.ttls[0] <= 0 || partial.ttls[0] >= complete.ttls[0] {
+		t.Fatalf("expected the partial response to expire sooner, got %v against %v", partial.ttls[0], complete.ttls[0])
+	}
+}
+
+func TestEmptyProviderResultsAreOK(t *testing.T) {
+	amadeus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/security/oauth2/token" {
+			_, _ = w.Write([]byte(`{"access_token":"token","expires_in":1799}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"meta":{"count":0},"data":[]}`))
+	}))
+	defer amadeus.Close()
+	google := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"best_flights":[],"other_flights":[]}`))
+	}))
+	defer google.Close()
+
+	provs := []providers.Provider{
+		providers.NewAmadeus(amadeus.Client(), amadeus.URL, "id", "secret"),
+		providers.NewGoogleFlights(google.Client(), google.URL, "key"),
+	}
+	cache := &ttlCache{}
+	resp, err := flights.NewService(provs, 5*time.Second, cache).Search(context.Background(), domain.SearchRequest{
+		Origin: "GRU", Destination: "JFK", StartDate: time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Partial || len(resp.Offers) != 0 {
+		t.Fatalf("expected a complete response without offers, got %+v", resp)
+	}
+	for _, st := range resp.Providers {
+		if st.Status != domain.ProviderOK || st.Quotes != 0 {
+			t.Fatalf("expected %s to be ok with 0 quotes, got %+v", st.Name, st)
+		}
+	}
+	if len(cache.ttls) != 1 || cache.ttls[0] < 30*time.Second {
+		t.Fatalf("expected the empty result to be cached like any complete one, got %v", cache.ttls)
+	}
+}
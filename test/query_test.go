@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+const googleFlightsFixture = `{"best_flights":[{"flights":[
+	{"departure_airport":{"id":"GRU","time":"2025-12-01 22:35"},
+	 "arrival_airport":{"id":"JFK","time":"2025-12-02 06:15"},
+	 "duration":580,"airline":"American"}],"price":907}]}`
+
+var richQuery = domain.SearchQuery{
+	Origin:        "GRU",
+	Destination:   "JFK",
+	DepartureDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+	ReturnDate:    time.Date(2025, 12, 10, 0, 0, 0, 0, time.UTC),
+	SearchOptions: domain.SearchOptions{
+		Adults:     2,
+		Children:   1,
+		Infants:    1,
+		Cabin:      domain.CabinBusiness,
+		NonStop:    true,
+		MaxResults: 5,
+		Currency:   "EUR",
+	},
+}
+
+func assertParams(t *testing.T, got url.Values, want map[string]string) {
+	t.Helper()
+	for k, v := range want {
+		if got.Get(k) != v {
+			t.Errorf("param %s: expected %q, got %q", k, v, got.Get(k))
+		}
+	}
+}
+
+func TestAmadeusTranslatesSearchQuery(t *testing.T) {
+	var got url.Values
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"t","expires_in":1799}`))
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(amadeusOffersFixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	if _, err := a.Search(context.Background(), richQuery); err != nil {
+		t.Fatal(err)
+	}
+
+	assertParams(t, got, map[string]string{
+		"originLocationCode":      "GRU",
+		"destinationLocationCode": "JFK",
+		"departureDate":           "2025-12-01",
+		"returnDate":              "2025-12-10",
+		"adults":                  "2",
+		"children":                "1",
+		"infants":                 "1",
+		"travelClass":             "BUSINESS",
+		"nonStop":                 "true",
+		"currencyCode":            "EUR",
+		"max":                     "5",
+	})
+}
+
+func TestGoogleFlightsTranslatesSearchQuery(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(googleFlightsFixture))
+	}))
+	defer srv.Close()
+
+	g := providers.NewGoogleFlights(srv.Client(), srv.URL, "key")
+	qs, err := g.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertParams(t, got, map[string]string{
+		"departure_id":    "GRU",
+		"arrival_id":      "JFK",
+		"outbound_date":   "2025-12-01",
+		"return_date":     "2025-12-10",
+		"adults":          "2",
+		"children":        "1",
+		"infants_on_lap":  "1",
+		"infants_in_seat": "",
+		"travel_class":    "3",
+		"stops":           "1",
+		"currency":        "EUR",
+	})
+	if qs[0].Price.Currency != "EUR" {
+		t.Fatalf("expected EUR quote, got %s", qs[0].Price.Currency)
+	}
+}
+
+func TestOneWayQueries(t *testing.T) {
+	oneWay := richQuery
+	oneWay.ReturnDate = time.Time{}
+
+	if !oneWay.OneWay() || richQuery.OneWay() {
+		t.Fatal("expected only the query without return date to be one-way")
+	}
+	if oneWay.Key() == richQuery.Key() {
+		t.Fatal("one-way and round-trip queries must not share a cache key")
+	}
+
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_, _ = w.Write([]byte(googleFlightsFixture))
+	}))
+	defer srv.Close()
+
+	g := providers.NewGoogleFlights(srv.Client(), srv.URL, "key")
+	if _, err := g.Search(context.Background(), oneWay); err != nil {
+		t.Fatal(err)
+	}
+	if got.Get("type") != "2" || got.Has("return_date") {
+		t.Fatalf("expected one-way SerpAPI request, got %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/config"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func TestBuildProvidersSkipsDisabledAndRejectsDuplicates(t *testing.T) {
+	provs, err := providers.Build([]providers.Config{
+		{Type: "mock", Name: "Ports Airlines", Enabled: true},
+		{Type: "googleflights", Enabled: false},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provs) != 1 || provs[0].Name() != "Ports Airlines" {
+		t.Fatalf("expected only the mock provider, got %v", provs)
+	}
+
+	_, err = providers.Build([]providers.Config{
+		{Type: "mock", Name: "Dup", Enabled: true},
+		{Type: "mock", Name: "Dup", Enabled: true},
+	})
+	if err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+
+	if _, err := providers.Build([]providers.Config{{Type: "skyscanner", Enabled: true}}); err == nil {
+		t.Fatal("expected unknown type error")
+	}
+}
+
+func TestAddProviderRejectsDuplicateNames(t *testing.T) {
+	svc := flights.NewService(nil, time.Second, flights.NewInMemoryTTL())
+	if err := svc.AddProvider(providers.NewMockProvider("Mock")); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddProvider(providers.NewMockProvider("Mock")); !errors.Is(err, flights.ErrDuplicateProvider) {
+		t.Fatalf("expected ErrDuplicateProvider, got %v", err)
+	}
+	if got := svc.ProviderNames(); len(got) != 1 {
+		t.Fatalf("expected 1 provider, got %v", got)
+	}
+}
+
+func TestConfigLoadFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	raw := `{"search_timeout":"5s","providers":[
+		{"type":"amadeus","enabled":true,"client_id":"${TEST_AMADEUS_ID}","client_secret":"${TEST_AMADEUS_SECRET}","timeout":"10s"}]}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	t.Setenv("TEST_AMADEUS_ID", "abc")
+	// JSON syntax in a variable stays part of the string
+	t.Setenv("TEST_AMADEUS_SECRET", `s3"cr\et","enabled":false,"x":"`)
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Duration(cfg.SearchTimeout) != 5*time.Second {
+		t.Fatalf("expected 5s search timeout, got %v", time.Duration(cfg.SearchTimeout))
+	}
+	if len(cfg.Providers) != 1 || cfg.Providers[0].ClientID != "abc" || !cfg.Providers[0].Enabled ||
+		cfg.Providers[0].ClientSecret != `s3"cr\et","enabled":false,"x":"` {
+		t.Fatalf("expected expanded amadeus config, got %+v", cfg.Providers)
+	}
+}
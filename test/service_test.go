@@ -18,7 +18,7 @@ type fakeProv struct {
 }
 
 func (f fakeProv) Name() string { return f.name }
-func (f fakeProv) Search(ctx context.Context, o, d string, dt, et time.Time) ([]domain.Quote, error) {
+func (f fakeProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
 	return f.qs, f.err
 }
 
@@ -29,7 +29,7 @@ func TestAggregationCheapestFastest(t *testing.T) {
 		qs: []domain.Quote{
 			{
 				Provider:    "amadeus",
-				Price:       100,
+				Price:       domain.NewMoney(10000, "USD"),
 				Duration:    3 * time.Hour,
 				DepartureAt: now,
 				ArrivalAt:   now.Add(3 * time.Hour),
@@ -43,7 +43,7 @@ func TestAggregationCheapestFastest(t *testing.T) {
 		qs: []domain.Quote{
 			{
 				Provider:    "Ports airlines",
-				Price:       120,
+				Price:       domain.NewMoney(12000, "USD"),
 				Duration:    2 * time.Hour,
 				DepartureAt: now,
 				ArrivalAt:   now.Add(2 * time.Hour),
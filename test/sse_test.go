@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+// noCache never stores, so every poll reaches the providers
+type noCache struct{}
+
+func (noCache) Get(string) (domain.AggregatedResponse, bool) {
+	return domain.AggregatedResponse{}, false
+}
+func (noCache) Set(string, domain.AggregatedResponse, time.Duration) {}
+func (noCache) Clear()                                               {}
+
+// risingProv raises its price by 10 USD on every call
+type risingProv struct{ calls atomic.Int64 }
+
+func (p *risingProv) Name() string { return "rising" }
+func (p *risingProv) Search(ctx context.Context, q domain.SearchQuery) ([]domain.Quote, error) {
+	n := p.calls.Add(1)
+	return []domain.Quote{{Provider: "rising", Airline: "LA", Price: domain.NewMoney(50000+n*1000, "USD")}}, nil
+}
+
+// streamFor serves the route stream until d elapses and returns the body
+func streamFor(t *testing.T, svc *flights.Service, path, lastEventID string, d time.Duration) (int, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	s := httpserver.New(svc, "secret")
+
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	req := httptest.NewRequest("GET", path, nil).WithContext(ctx)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		s.Engine().ServeHTTP(w, req)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(d + 2*time.Second):
+		t.Fatal("stream did not stop after the client went away")
+	}
+	return w.Code, w.Body.String()
+}
+
+func TestRouteStreamEmitsUpdatesAndDiffs(t *testing.T) {
+	svc := flights.NewService([]providers.Provider{&risingProv{}}, 5*time.Second, noCache{})
+	code, body := streamFor(t, svc, "/sse/GRU|JFK|2025-12-01?interval=1s", "", 1500*time.Millisecond)
+
+	if code != 200 {
+		t.Fatalf("expected 200, got %d: %s", code, body)
+	}
+	if n := strings.Count(body, "event:update"); n != 2 {
+		t.Fatalf("expected 2 updates, got %d:\n%s", n, body)
+	}
+	if !strings.Contains(body, "event:diff") || !strings.Contains(body, `"change":"up"`) {
+		t.Fatalf("expected a diff with a price increase:\n%s", body)
+	}
+	if !strings.Contains(body, "id:") {
+		t.Fatalf("expected updates to carry an event id:\n%s", body)
+	}
+}
+
+func TestRouteStreamResumesFromLastEventID(t *testing.T) {
+	static := fakeProv{name: "static", qs: []domain.Quote{{Provider: "static", Price: domain.NewMoney(50000, "USD")}}}
+	svc := flights.NewService([]providers.Provider{static}, 5*time.Second, noCache{})
+	req := domain.SearchRequest{Origin: "GRU", Destination: "JFK", StartDate: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)}
+	resp, err := svc.Search(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, body := streamFor(t, svc, "/sse/GRU|JFK|2025-12-01?interval=1s", flights.Version(resp.Offers), 1500*time.Millisecond)
+	if strings.Contains(body, "event:update") {
+		t.Fatalf("expected no update for an unchanged result:\n%s", body)
+	}
+}
+
+func TestRouteStreamRejectsInvalidRoute(t *testing.T) {
+	svc := flights.NewService(nil, 5*time.Second, noCache{})
+	for _, path := range []string{
+		"/sse/GRU|JFK|2025-13-01",
+		"/sse/GRU|JFK",
+		"/sse/XYZ|JFK|2025-12-01",
+		"/sse/GRU|JFK|2025-12-01?interval=1ms",
+	} {
+		if code, body := streamFor(t, svc, path, "", time.Second); code != 400 {
+			t.Fatalf("%s: expected 400, got %d: %s", path, code, body)
+		}
+	}
+}
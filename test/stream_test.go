@@ -0,0 +1,74 @@
+package test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/http/middleware"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func authHeader(t *testing.T, secret string) string {
+	t.Helper()
+	token, err := middleware.GenerateJWT(secret, "tester", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+func TestSearchStreamNDJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fast := fakeProv{name: "fast", qs: []domain.Quote{{Provider: "fast", Price: domain.NewMoney(70000, "USD")}}}
+	failing := fakeProv{name: "failing", err: &providers.StatusError{Provider: "failing", Code: 503}}
+	svc := flights.NewService([]providers.Provider{fast, failing}, 5*time.Second, flights.NewInMemoryTTL())
+	s := httpserver.New(svc, "secret")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/flights/search/stream?format=ndjson&origin=GRU&destination=JFK&starDate=2025-12-01", nil)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	s.Engine().ServeHTTP(w, req)
+
+	if w.Code != 200 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/x-ndjson") {
+		t.Fatalf("unexpected response %d %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+
+	var events []string
+	sc := bufio.NewScanner(w.Body)
+	for sc.Scan() {
+		var ev struct {
+			Event string          `json:"event"`
+			Data  json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatalf("invalid line %q: %v", sc.Text(), err)
+		}
+		events = append(events, ev.Event)
+	}
+	if strings.Join(events, ",") != "provider,provider,summary" {
+		t.Fatalf("unexpected events %v", events)
+	}
+}
+
+func TestSearchStreamRejectsUnknownAirport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc := flights.NewService([]providers.Provider{fakeProv{name: "p"}}, 5*time.Second, flights.NewInMemoryTTL())
+	s := httpserver.New(svc, "secret")
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/flights/search/stream?origin=XYZ&destination=JFK&starDate=2025-12-01", nil)
+	req.Header.Set("Authorization", authHeader(t, "secret"))
+	s.Engine().ServeHTTP(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400 before streaming, got %d", w.Code)
+	}
+}
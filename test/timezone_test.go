@@ -0,0 +1,110 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/poportss/go-challenge-flight-price/internal/airports"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+func TestAirportLocation(t *testing.T) {
+	if loc, ok := airports.Location("jfk"); !ok || loc.String() != "America/New_York" {
+		t.Fatalf("expected America/New_York, got %v", loc)
+	}
+	if loc, ok := airports.Location("ZZZ"); ok {
+		t.Fatalf("expected no time zone for an unknown airport, got %v", loc)
+	}
+	if _, err := airports.ParseLocal("2006-01-02 15:04", "2025-12-01 22:35", "ZZZ"); !errors.Is(err, airports.ErrUnknownTimezone) {
+		t.Fatalf("expected ErrUnknownTimezone, got %v", err)
+	}
+}
+
+func TestAmadeusDurationsAcrossTimezones(t *testing.T) {
+	// GRU 22:00 (UTC-3) → JFK 06:00 (UTC-5) is 10 hours of flying, not 8
+	fixture := `{"data":[{"itineraries":[{"duration":"PT10H","segments":[
+		{"departure":{"iataCode":"GRU","at":"2025-12-01T22:00:00"},
+		 "arrival":{"iataCode":"JFK","at":"2025-12-02T06:00:00"},
+		 "carrierCode":"LA","number":"8180","duration":"PT10H"}]}],
+		"price":{"currency":"USD","grandTotal":"812.40"}}]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/security/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"access_token":"t","expires_in":1799}`))
+	})
+	mux.HandleFunc("/v2/shopping/flight-offers", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fixture))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	a := providers.NewAmadeus(srv.Client(), srv.URL, "id", "secret")
+	qs, err := a.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := qs[0]
+	if q.Duration != 10*time.Hour {
+		t.Fatalf("expected 10h, got %v", q.Duration)
+	}
+	if got := q.DepartureAtUTC; !got.Equal(time.Date(2025, 12, 2, 1, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected UTC departure %v", got)
+	}
+	if got := q.ArrivalAt.Format("15:04 -0700"); got != "06:00 -0500" {
+		t.Fatalf("expected local arrival 06:00 -0500, got %s", got)
+	}
+}
+
+func TestGoogleFlightsUsesProviderDurations(t *testing.T) {
+	fixture := `{"best_flights":[{"flights":[
+		{"departure_airport":{"id":"GRU","time":"2025-12-01 22:35"},
+		 "arrival_airport":{"id":"JFK","time":"2025-12-02 06:15"},
+		 "duration":580,"airline":"American","flight_number":"AA 906"}],"total_duration":580,"price":907}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fixture))
+	}))
+	defer srv.Close()
+
+	g := providers.NewGoogleFlights(srv.Client(), srv.URL, "key")
+	qs, err := g.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if qs[0].Duration != 580*time.Minute {
+		t.Fatalf("expected 9h40m, got %v", qs[0].Duration)
+	}
+}
+
+func TestGoogleFlightsPlacesUnknownAirportsFromDurations(t *testing.T) {
+	// ZZZ is not in the reference data: its offset comes from GRU and the
+	// 9h40m flight, while a leg between two unknown airports can't be placed
+	fixture := `{"best_flights":[{"flights":[
+		{"departure_airport":{"id":"GRU","time":"2025-12-01 22:35"},
+		 "arrival_airport":{"id":"ZZZ","time":"2025-12-02 06:15"},
+		 "duration":580,"airline":"American","flight_number":"AA 906"}],"total_duration":580,"price":907},
+		{"flights":[
+		{"departure_airport":{"id":"YYY","time":"2025-12-01 10:00"},
+		 "arrival_airport":{"id":"ZZZ","time":"2025-12-01 14:00"},
+		 "duration":240,"airline":"American","flight_number":"AA 100"}],"total_duration":240,"price":300}]}`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(fixture))
+	}))
+	defer srv.Close()
+
+	g := providers.NewGoogleFlights(srv.Client(), srv.URL, "key")
+	qs, err := g.Search(context.Background(), richQuery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(qs) != 1 {
+		t.Fatalf("expected only the offer with a known airport, got %d", len(qs))
+	}
+	if got := qs[0].ArrivalAt.Format("2006-01-02 15:04 -0700"); got != "2025-12-02 06:15 -0500" {
+		t.Fatalf("expected the arrival at 06:15 -0500, got %s", got)
+	}
+}
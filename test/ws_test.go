@@ -0,0 +1,151 @@
+package test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/poportss/go-challenge-flight-price/internal/domain"
+	"github.com/poportss/go-challenge-flight-price/internal/flights"
+	httpserver "github.com/poportss/go-challenge-flight-price/internal/http"
+	"github.com/poportss/go-challenge-flight-price/internal/providers"
+)
+
+type wsMsg struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Route string `json:"route"`
+	Error string `json:"error"`
+}
+
+func dialWatch(t *testing.T, url string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/ws/watch", header)
+}
+
+// nextOfType reads messages until one of the given type arrives
+func nextOfType(t *testing.T, conn *websocket.Conn, typ string) wsMsg {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		var m wsMsg
+		if err := conn.ReadJSON(&m); err != nil {
+			t.Fatalf("waiting for %s: %v", typ, err)
+		}
+		if m.Type == typ {
+			return m
+		}
+	}
+}
+
+func TestWatchWebSocket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	static := fakeProv{name: "static", qs: []domain.Quote{{Provider: "static", Price: domain.NewMoney(50000, "USD")}}}
+	svc := flights.NewService([]providers.Provider{static}, 5*time.Second, flights.NewInMemoryTTL())
+	srv := httptest.NewServer(httpserver.New(svc, "secret").Engine())
+	defer srv.Close()
+
+	if _, resp, err := dialWatch(t, srv.URL, nil); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the handshake to require a JWT, got %v", err)
+	}
+
+	conn, _, err := dialWatch(t, srv.URL, http.Header{"Authorization": {authHeader(t, "secret")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	send := func(v any) {
+		if err := conn.WriteJSON(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	search := func(origin, dest string) map[string]any {
+		return map[string]any{"origin": origin, "destination": dest, "startDate": "2025-12-01"}
+	}
+
+	send(map[string]any{"type": "subscribe", "id": "bad", "search": search("XYZ", "JFK")})
+	if m := nextOfType(t, conn, "error"); m.ID != "bad" {
+		t.Fatalf("expected an error for the unknown airport, got %+v", m)
+	}
+
+	send(map[string]any{"type": "subscribe", "id": "a", "search": search("GRU", "JFK")})
+	send(map[string]any{"type": "subscribe", "id": "b", "search": search("GRU", "LIS"), "interval": "1m"})
+
+	seen := map[string]bool{}
+	for len(seen) < 2 {
+		m := nextOfType(t, conn, "update")
+		seen[m.ID] = true
+	}
+
+	send(map[string]any{"type": "unsubscribe", "id": "a"})
+	if m := nextOfType(t, conn, "unsubscribed"); m.ID != "a" {
+		t.Fatalf("unexpected %+v", m)
+	}
+}
+
+// lockedBuffer is a bytes.Buffer safe to write from the server's goroutines
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchHandshakeChecksOriginAndHidesToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logs := &lockedBuffer{}
+	gin.DefaultWriter = logs
+	defer func() { gin.DefaultWriter = os.Stdout }()
+
+	svc := flights.NewService([]providers.Provider{fakeProv{name: "static"}}, 5*time.Second, flights.NewInMemoryTTL())
+	srv := httptest.NewServer(httpserver.New(svc, "secret", httpserver.WithAllowedOrigins("https://app.example.com")).Engine())
+	defer srv.Close()
+
+	token := strings.TrimPrefix(authHeader(t, "secret"), "Bearer ")
+	dial := func(origin string) (*http.Response, error) {
+		conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws/watch?access_token="+token, http.Header{"Origin": {origin}})
+		if err == nil {
+			conn.Close()
+		}
+		return resp, err
+	}
+
+	if resp, err := dial("https://evil.example.com"); err == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a foreign origin to be refused, got %v", err)
+	}
+	for _, origin := range []string{"https://app.example.com", srv.URL} {
+		if _, err := dial(origin); err != nil {
+			t.Fatalf("expected %s to be allowed, got %v", origin, err)
+		}
+	}
+
+	// upgraded connections are logged once their handler returns
+	deadline := time.Now().Add(time.Second)
+	for strings.Count(logs.String(), "/ws/watch") < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if strings.Contains(logs.String(), token) {
+		t.Fatalf("the access token was logged: %s", logs.String())
+	}
+	if !strings.Contains(logs.String(), "access_token=REDACTED") {
+		t.Fatalf("expected the redacted request to be logged, got %s", logs.String())
+	}
+}